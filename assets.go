@@ -0,0 +1,249 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/nekoPhysics/figma_to_codev1/auth"
+	"github.com/nekoPhysics/figma_to_codev1/figmaclient"
+)
+
+// needsAsset はノードがレンダリング対象の書き出しを必要とするかを判定します。
+// IMAGE/VECTOR/COMPONENT/INSTANCEは常に書き出し対象ですが、RECTANGLEは
+// 画像フィル（fills に type:"IMAGE" を含む）を持つ場合だけが対象です。
+// 単なる単色/グラデーションの矩形まで全てラスタライズすると、実際のデザインの
+// 大半を占めるボタンやカードの背景ごとにimages APIを叩いてしまうためです。
+func needsAsset(l LayerInfo) bool {
+	switch l.Type {
+	case "IMAGE", "VECTOR", "COMPONENT", "INSTANCE":
+		return true
+	case "RECTANGLE":
+		return hasImageFill(l.Fills)
+	default:
+		return false
+	}
+}
+
+// hasImageFill はfillsに画像塗りつぶし（type:"IMAGE"）が含まれるかを判定します。
+func hasImageFill(fills []FigmaPaint) bool {
+	for _, f := range fills {
+		if f.Type == "IMAGE" {
+			return true
+		}
+	}
+	return false
+}
+
+// collectAssetNodeIDs はlayersのうちラスタライズが必要なノードのIDを集めます。
+func collectAssetNodeIDs(layers []LayerInfo) []string {
+	var ids []string
+	for _, l := range layers {
+		if needsAsset(l) {
+			ids = append(ids, l.ID)
+		}
+	}
+	return ids
+}
+
+// figmaImagesResponse は images API のレスポンスをマッピングする構造体です。
+type figmaImagesResponse struct {
+	Err    string            `json:"err"`
+	Images map[string]string `json:"images"`
+}
+
+// fetchImageURLs は GET /v1/images/{file_id} を呼び出し、ノードIDごとの
+// レンダリング済みアセットURL（S3）を取得します。
+func fetchImageURLs(client *figmaclient.Client, tokenSource *auth.TokenSource, fileID string, nodeIDs []string, format string, scale int) (map[string]string, error) {
+	if len(nodeIDs) == 0 {
+		return map[string]string{}, nil
+	}
+
+	apiURL := fmt.Sprintf("https://api.figma.com/v1/images/%s?ids=%s&format=%s&scale=%d",
+		fileID, strings.Join(nodeIDs, ","), format, scale)
+
+	req, err := http.NewRequest("GET", apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("images APIリクエストの作成に失敗しました: %w", err)
+	}
+	if err := tokenSource.SetHeader(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("images APIリクエストの実行に失敗しました: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("images APIがエラーを返しました: %s\n%s", resp.Status, string(body))
+	}
+
+	var parsed figmaImagesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("images APIレスポンスのデコードに失敗しました: %w", err)
+	}
+	if parsed.Err != "" {
+		return nil, fmt.Errorf("images APIがエラーを返しました: %s", parsed.Err)
+	}
+
+	return parsed.Images, nil
+}
+
+// downloadAssetsConcurrently はurlsの各アセットをparallel個のワーカーでdirへダウンロードし、
+// ノードIDからダウンロード済みローカルパスへのマップを返します。
+func downloadAssetsConcurrently(client *figmaclient.Client, urls map[string]string, ext, dir string, parallel int) (map[string]string, error) {
+	if parallel < 1 {
+		parallel = 1
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("アセットディレクトリの作成に失敗しました: %w", err)
+	}
+
+	type job struct {
+		nodeID string
+		url    string
+	}
+	jobs := make(chan job)
+	results := make(map[string]string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+
+	for i := 0; i < parallel; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				path, err := downloadAsset(client, j.url, ext, dir, j.nodeID)
+				mu.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = fmt.Errorf("ノード %s のダウンロードに失敗しました: %w", j.nodeID, err)
+					}
+				} else {
+					results[j.nodeID] = path
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for nodeID, url := range urls {
+		if url == "" {
+			continue
+		}
+		jobs <- job{nodeID: nodeID, url: url}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return results, firstErr
+	}
+	return results, nil
+}
+
+// downloadAsset は1件のアセットをurlからdir配下にファイル名 "<nodeID>.<ext>" として保存します。
+func downloadAsset(client *figmaclient.Client, url, ext, dir, nodeID string) (string, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("アセットの取得に失敗しました: %s", resp.Status)
+	}
+
+	safeName := strings.ReplaceAll(nodeID, ":", "_")
+	outPath := filepath.Join(dir, fmt.Sprintf("%s.%s", safeName, ext))
+
+	out, err := os.Create(outPath)
+	if err != nil {
+		return "", err
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, resp.Body); err != nil {
+		return "", err
+	}
+	return outPath, nil
+}
+
+// fetchAndEmbedAssets はlayersのうちレンダリング対象のノードを検出し、画像/ベクター
+// アセットをassetsディレクトリへダウンロードした上で、LayerInfoにローカルパスを書き戻します。
+// rasterFormatはラスターノード（IMAGE/RECTANGLE/COMPONENT/INSTANCE）の書き出し形式
+// （"png"または"jpg"）、scaleは書き出し倍率（1, 2, 3のいずれか）です。VECTORは常にSVGで
+// 書き出します。
+func fetchAndEmbedAssets(client *figmaclient.Client, tokenSource *auth.TokenSource, fileID string, layers []LayerInfo, assetsDir string, parallel int, rasterFormat string, scale int) error {
+	if rasterFormat != "png" && rasterFormat != "jpg" {
+		return fmt.Errorf("不明な asset-format です: %s（png または jpg を指定してください）", rasterFormat)
+	}
+	if scale < 1 || scale > 3 {
+		return fmt.Errorf("不明な asset-scale です: %d（1, 2, 3 のいずれかを指定してください）", scale)
+	}
+
+	nodeIDs := collectAssetNodeIDs(layers)
+	if len(nodeIDs) == 0 {
+		return nil
+	}
+
+	// VECTORはSVG、それ以外は指定されたrasterFormat/scaleでまとめて取得します。
+	var vectorIDs, rasterIDs []string
+	for _, l := range layers {
+		if !needsAsset(l) {
+			continue
+		}
+		if l.Type == "VECTOR" {
+			vectorIDs = append(vectorIDs, l.ID)
+		} else {
+			rasterIDs = append(rasterIDs, l.ID)
+		}
+	}
+
+	paths := make(map[string]string)
+
+	if len(rasterIDs) > 0 {
+		urls, err := fetchImageURLs(client, tokenSource, fileID, rasterIDs, rasterFormat, scale)
+		if err != nil {
+			return fmt.Errorf("%sアセットURLの取得に失敗しました: %w", strings.ToUpper(rasterFormat), err)
+		}
+		downloaded, err := downloadAssetsConcurrently(client, urls, rasterFormat, assetsDir, parallel)
+		if err != nil {
+			return err
+		}
+		for id, p := range downloaded {
+			paths[id] = p
+		}
+	}
+
+	if len(vectorIDs) > 0 {
+		urls, err := fetchImageURLs(client, tokenSource, fileID, vectorIDs, "svg", 1)
+		if err != nil {
+			return fmt.Errorf("SVGアセットURLの取得に失敗しました: %w", err)
+		}
+		downloaded, err := downloadAssetsConcurrently(client, urls, "svg", assetsDir, parallel)
+		if err != nil {
+			return err
+		}
+		for id, p := range downloaded {
+			paths[id] = p
+		}
+	}
+
+	for i := range layers {
+		if p, ok := paths[layers[i].ID]; ok {
+			layers[i].AssetPath = p
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestNeedsAssetOnlyRasterizesImageFilledRectangles(t *testing.T) {
+	cases := []struct {
+		name string
+		l    LayerInfo
+		want bool
+	}{
+		{name: "plain rectangle", l: LayerInfo{Type: "RECTANGLE"}, want: false},
+		{
+			name: "solid-fill rectangle",
+			l:    LayerInfo{Type: "RECTANGLE", Fills: []FigmaPaint{{Type: "SOLID", Color: &FigmaColor{}}}},
+			want: false,
+		},
+		{
+			name: "image-fill rectangle",
+			l:    LayerInfo{Type: "RECTANGLE", Fills: []FigmaPaint{{Type: "IMAGE"}}},
+			want: true,
+		},
+		{name: "image node", l: LayerInfo{Type: "IMAGE"}, want: true},
+		{name: "vector node", l: LayerInfo{Type: "VECTOR"}, want: true},
+		{name: "text node", l: LayerInfo{Type: "TEXT"}, want: false},
+	}
+	for _, c := range cases {
+		if got := needsAsset(c.l); got != c.want {
+			t.Errorf("needsAsset(%s) = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+// Package auth はFigmaへの認証（個人アクセストークンおよびOAuth2）を扱います。
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Credentials はOAuth2のトークンペアをディスクに永続化するための形です。
+type Credentials struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// Expired はアクセストークンの有効期限が切れているか（余裕を見て30秒前から）を返します。
+func (c Credentials) Expired() bool {
+	return time.Now().After(c.ExpiresAt.Add(-30 * time.Second))
+}
+
+// CredentialsPath は資格情報ファイルの保存先を返します。
+// $XDG_CONFIG_HOME/figma-to-code/credentials.json を優先し、
+// 未設定の場合は $HOME/.config/figma-to-code/credentials.json を使います。
+func CredentialsPath() (string, error) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("ホームディレクトリの取得に失敗しました: %w", err)
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configHome, "figma-to-code", "credentials.json"), nil
+}
+
+// LoadCredentials はディスクから資格情報を読み込みます。存在しない場合はokがfalseになります。
+func LoadCredentials() (Credentials, bool, error) {
+	path, err := CredentialsPath()
+	if err != nil {
+		return Credentials{}, false, err
+	}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Credentials{}, false, nil
+	}
+	if err != nil {
+		return Credentials{}, false, fmt.Errorf("資格情報の読み込みに失敗しました: %w", err)
+	}
+
+	var creds Credentials
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return Credentials{}, false, fmt.Errorf("資格情報のデコードに失敗しました: %w", err)
+	}
+	return creds, true, nil
+}
+
+// SaveCredentials は資格情報を0600権限でディスクに書き込みます。
+func SaveCredentials(creds Credentials) error {
+	path, err := CredentialsPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("設定ディレクトリの作成に失敗しました: %w", err)
+	}
+
+	data, err := json.MarshalIndent(creds, "", "  ")
+	if err != nil {
+		return fmt.Errorf("資格情報のシリアライズに失敗しました: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("資格情報の書き込みに失敗しました: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	authorizeURL = "https://www.figma.com/oauth"
+	tokenURL     = "https://www.figma.com/api/oauth/token"
+)
+
+// LoginOptions はOAuth2フローに必要なクライアント情報です。
+// client_id/client_secretはFigmaの開発者コンソールでアプリを登録して取得します。
+type LoginOptions struct {
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// OpenBrowser はブラウザで認可URLを開く処理です。テストや非対話環境での差し替え用です。
+	OpenBrowser func(rawURL string) error
+}
+
+// Login はループバックHTTPサーバーを立ち上げ、ブラウザでFigmaの認可画面を開き、
+// コールバックで受け取った認可コードをアクセストークンに交換します。
+func Login(ctx context.Context, opts LoginOptions) (Credentials, error) {
+	state, err := randomState()
+	if err != nil {
+		return Credentials{}, fmt.Errorf("stateの生成に失敗しました: %w", err)
+	}
+
+	codeCh := make(chan string, 1)
+	errCh := make(chan error, 1)
+
+	listener, err := newLoopbackListener()
+	if err != nil {
+		return Credentials{}, fmt.Errorf("ループバックリスナーの起動に失敗しました: %w", err)
+	}
+	redirectURI := fmt.Sprintf("http://127.0.0.1:%d/callback", listener.Port())
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("state") != state {
+			errCh <- fmt.Errorf("stateが一致しません（CSRFの可能性があります）")
+			http.Error(w, "invalid state", http.StatusBadRequest)
+			return
+		}
+		if errMsg := query.Get("error"); errMsg != "" {
+			errCh <- fmt.Errorf("Figmaが認可を拒否しました: %s", errMsg)
+			http.Error(w, errMsg, http.StatusBadRequest)
+			return
+		}
+		code := query.Get("code")
+		if code == "" {
+			errCh <- fmt.Errorf("認可コードが含まれていません")
+			http.Error(w, "missing code", http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintln(w, "ログインが完了しました。このタブを閉じてCLIに戻ってください。")
+		codeCh <- code
+	})
+
+	srv := &http.Server{Handler: mux}
+	go srv.Serve(listener)
+	defer srv.Close()
+
+	authURL := buildAuthorizeURL(opts.ClientID, redirectURI, opts.Scopes, state)
+	openBrowser := opts.OpenBrowser
+	if openBrowser == nil {
+		openBrowser = defaultOpenBrowser
+	}
+	if err := openBrowser(authURL); err != nil {
+		fmt.Printf("ブラウザを自動で開けませんでした。以下のURLを開いてください:\n%s\n", authURL)
+	}
+
+	select {
+	case code := <-codeCh:
+		return exchangeCode(ctx, opts, code, redirectURI)
+	case err := <-errCh:
+		return Credentials{}, err
+	case <-ctx.Done():
+		return Credentials{}, ctx.Err()
+	}
+}
+
+// buildAuthorizeURL はFigmaのOAuth2認可画面へのURLを組み立てます。
+func buildAuthorizeURL(clientID, redirectURI string, scopes []string, state string) string {
+	v := url.Values{}
+	v.Set("client_id", clientID)
+	v.Set("redirect_uri", redirectURI)
+	v.Set("scope", strings.Join(scopes, ","))
+	v.Set("state", state)
+	v.Set("response_type", "code")
+	return authorizeURL + "?" + v.Encode()
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// exchangeCode は認可コードをアクセストークン/リフレッシュトークンに交換します。
+func exchangeCode(ctx context.Context, opts LoginOptions, code, redirectURI string) (Credentials, error) {
+	form := url.Values{}
+	form.Set("client_id", opts.ClientID)
+	form.Set("client_secret", opts.ClientSecret)
+	form.Set("redirect_uri", redirectURI)
+	form.Set("code", code)
+	form.Set("grant_type", "authorization_code")
+
+	req, err := http.NewRequestWithContext(ctx, "POST", tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Credentials{}, fmt.Errorf("トークン交換リクエストの作成に失敗しました: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("トークン交換リクエストの実行に失敗しました: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Credentials{}, fmt.Errorf("トークン交換がエラーを返しました: %s", resp.Status)
+	}
+
+	var parsed tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return Credentials{}, fmt.Errorf("トークンレスポンスのデコードに失敗しました: %w", err)
+	}
+
+	return Credentials{
+		AccessToken:  parsed.AccessToken,
+		RefreshToken: parsed.RefreshToken,
+		ExpiresAt:    time.Now().Add(time.Duration(parsed.ExpiresIn) * time.Second),
+	}, nil
+}
+
+// Refresh はリフレッシュトークンを使って新しいアクセストークンを取得します。
+func Refresh(ctx context.Context, opts LoginOptions, creds Credentials) (Credentials, error) {
+	form := url.Values{}
+	form.Set("client_id", opts.ClientID)
+	form.Set("client_secret", opts.ClientSecret)
+	form.Set("refresh_token", creds.RefreshToken)
+	form.Set("grant_type", "refresh_token")
+
+	req, err := http.NewRequestWithContext(ctx, "POST", tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Credentials{}, fmt.Errorf("トークン更新リクエストの作成に失敗しました: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("トークン更新リクエストの実行に失敗しました: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Credentials{}, fmt.Errorf("トークン更新がエラーを返しました: %s", resp.Status)
+	}
+
+	var parsed tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return Credentials{}, fmt.Errorf("トークンレスポンスのデコードに失敗しました: %w", err)
+	}
+
+	refreshToken := parsed.RefreshToken
+	if refreshToken == "" {
+		refreshToken = creds.RefreshToken
+	}
+	return Credentials{
+		AccessToken:  parsed.AccessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    time.Now().Add(time.Duration(parsed.ExpiresIn) * time.Second),
+	}, nil
+}
+
+func randomState() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"net"
+	"os/exec"
+	"runtime"
+)
+
+// loopbackListener はOSが割り当てたランダムなポートで待ち受けるnet.Listenerのラッパーです。
+type loopbackListener struct {
+	net.Listener
+	port int
+}
+
+func newLoopbackListener() (*loopbackListener, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	return &loopbackListener{Listener: ln, port: ln.Addr().(*net.TCPAddr).Port}, nil
+}
+
+// Port は実際に割り当てられたポート番号を返します。
+func (l *loopbackListener) Port() int {
+	return l.port
+}
+
+// defaultOpenBrowser はOSごとのコマンドでデフォルトブラウザにrawURLを開かせます。
+func defaultOpenBrowser(rawURL string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", rawURL)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", rawURL)
+	default:
+		cmd = exec.Command("xdg-open", rawURL)
+	}
+	return cmd.Start()
+}
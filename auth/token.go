@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+)
+
+// TokenSource はFigma APIリクエストに適切な認証ヘッダーを設定します。
+// 個人アクセストークン（X-Figma-Token）とOAuth2アクセストークン（Authorization: Bearer）の
+// どちらの経路でも透過的に使えるよう、呼び出し側はTokenSourceだけを扱います。
+type TokenSource struct {
+	personalAccessToken string
+	oauthOpts           LoginOptions
+	oauthCreds          Credentials
+}
+
+// SetHeader はreqに適切な認証ヘッダーを設定します。OAuth資格情報の有効期限が
+// 切れている場合はリフレッシュしてディスクに保存し直します。
+func (t *TokenSource) SetHeader(req *http.Request) error {
+	if t.personalAccessToken != "" {
+		req.Header.Set("X-Figma-Token", t.personalAccessToken)
+		return nil
+	}
+
+	if t.oauthCreds.Expired() {
+		refreshed, err := Refresh(req.Context(), t.oauthOpts, t.oauthCreds)
+		if err != nil {
+			return fmt.Errorf("OAuthアクセストークンの更新に失敗しました: %w", err)
+		}
+		if err := SaveCredentials(refreshed); err != nil {
+			return fmt.Errorf("更新した資格情報の保存に失敗しました: %w", err)
+		}
+		t.oauthCreds = refreshed
+	}
+
+	req.Header.Set("Authorization", "Bearer "+t.oauthCreds.AccessToken)
+	return nil
+}
+
+// Resolve は認証方法を決定します。FIGMA_API_TOKEN環境変数が最優先で、
+// 次にディスクに保存されたOAuth資格情報を使います。どちらも無い場合はエラーを返し、
+// `login` サブコマンドの実行を促します。
+func Resolve(ctx context.Context, oauthOpts LoginOptions) (*TokenSource, error) {
+	if pat := os.Getenv("FIGMA_API_TOKEN"); pat != "" {
+		return &TokenSource{personalAccessToken: pat}, nil
+	}
+
+	creds, ok, err := LoadCredentials()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("認証情報が見つかりません。FIGMA_API_TOKEN を設定するか、`login` サブコマンドでOAuthログインしてください")
+	}
+
+	return &TokenSource{oauthOpts: oauthOpts, oauthCreds: creds}, nil
+}
@@ -0,0 +1,110 @@
+// Package cache はFigmaファイルのバージョン情報と直近の抽出結果を
+// ローカルのJSONサイドカーファイルとして永続化します。
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Entry は1つの(fileID, pageName)の組に対応するキャッシュの中身です。
+type Entry struct {
+	// PageName はこのEntryが対応するページ名です。ファイル内の別ページのEntryと
+	// 取り違えていないことを確認するために保持します。
+	PageName string `json:"page_name"`
+	// VersionID は最後に見たバージョンID（GET /v1/files/{key}/versions のtop要素）です。
+	VersionID string `json:"version_id"`
+	// CanvasModified はトップレベルキャンバス子ノードごとの lastModified を記録し、
+	// 次回の差分検出（どのフレームが変わったか）に使います。
+	CanvasModified map[string]string `json:"canvas_modified"`
+	// Layers は直近に書き出したlayers.json相当のデータです（LayerInfoのJSON配列）。
+	Layers json.RawMessage `json:"layers"`
+}
+
+// Store は(fileID, pageName)の組ごとのEntryをディスク上のディレクトリに保存します。
+type Store struct {
+	dir string
+}
+
+// NewStore はdir配下にキャッシュを保持するStoreを作ります。
+// dirが存在しない場合は作成します。
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("キャッシュディレクトリの作成に失敗しました: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// path は(fileID, pageName)の組ごとに別のキャッシュファイルを割り当てます。
+// pageNameはファイル名として安全でない文字を含み得るため、そのままでは使わず
+// ハッシュ化してfileIDに付加します。同じファイルの別ページを取り違えないための
+// キー設計です。
+func (s *Store) path(fileID, pageName string) string {
+	sum := sha256.Sum256([]byte(pageName))
+	return filepath.Join(s.dir, fmt.Sprintf("%s-%s.json", fileID, hex.EncodeToString(sum[:8])))
+}
+
+// Load はfileIDとpageNameに対応するEntryを読み込みます。キャッシュが存在しない場合は
+// ok が false になります。
+func (s *Store) Load(fileID, pageName string) (Entry, bool, error) {
+	data, err := os.ReadFile(s.path(fileID, pageName))
+	if os.IsNotExist(err) {
+		return Entry{}, false, nil
+	}
+	if err != nil {
+		return Entry{}, false, fmt.Errorf("キャッシュの読み込みに失敗しました: %w", err)
+	}
+
+	var entry Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return Entry{}, false, fmt.Errorf("キャッシュのデコードに失敗しました: %w", err)
+	}
+	if entry.PageName != pageName {
+		// ハッシュ衝突やキャッシュディレクトリの使い回しで別ページのEntryを
+		// 拾ってしまった場合は、無いものとして扱いフル同期させます。
+		return Entry{}, false, nil
+	}
+	return entry, true, nil
+}
+
+// Save はfileIDとpageNameに対応するEntryをディスクに書き込みます。
+func (s *Store) Save(fileID, pageName string, entry Entry) error {
+	entry.PageName = pageName
+	data, err := json.MarshalIndent(entry, "", "  ")
+	if err != nil {
+		return fmt.Errorf("キャッシュのシリアライズに失敗しました: %w", err)
+	}
+	if err := os.WriteFile(s.path(fileID, pageName), data, 0644); err != nil {
+		return fmt.Errorf("キャッシュの書き込みに失敗しました: %w", err)
+	}
+	return nil
+}
+
+// ChangedCanvases はcurrentのlastModifiedマップとキャッシュ済みのEntryを比較し、
+// 新規追加または更新されたトップレベルキャンバスIDの一覧を返します。
+func ChangedCanvases(cached Entry, current map[string]string) []string {
+	var changed []string
+	for id, modified := range current {
+		if prev, ok := cached.CanvasModified[id]; !ok || prev != modified {
+			changed = append(changed, id)
+		}
+	}
+	return changed
+}
+
+// DeletedCanvases はキャッシュ済みのEntryにはあったが、currentのlastModifiedマップには
+// もう存在しないトップレベルキャンバスIDの一覧を返します。Figma側でフレームが削除された
+// 場合に該当し、呼び出し側はそのサブツリーをキャッシュ済みレイヤーから取り除く必要があります。
+func DeletedCanvases(cached Entry, current map[string]string) []string {
+	var deleted []string
+	for id := range cached.CanvasModified {
+		if _, ok := current[id]; !ok {
+			deleted = append(deleted, id)
+		}
+	}
+	return deleted
+}
@@ -0,0 +1,44 @@
+package cache
+
+import "testing"
+
+func TestChangedCanvasesDetectsAddedAndModified(t *testing.T) {
+	cached := Entry{CanvasModified: map[string]string{
+		"frameA": "2026-01-01T00:00:00Z",
+		"frameB": "2026-01-01T00:00:00Z",
+	}}
+	current := map[string]string{
+		"frameA": "2026-01-01T00:00:00Z", // unchanged
+		"frameB": "2026-01-02T00:00:00Z", // modified
+		"frameC": "2026-01-03T00:00:00Z", // added
+	}
+
+	got := ChangedCanvases(cached, current)
+
+	changed := make(map[string]bool, len(got))
+	for _, id := range got {
+		changed[id] = true
+	}
+	if changed["frameA"] {
+		t.Error("ChangedCanvases() unexpectedly reported unchanged frameA")
+	}
+	if !changed["frameB"] || !changed["frameC"] {
+		t.Errorf("ChangedCanvases() = %v, want frameB and frameC", got)
+	}
+}
+
+func TestDeletedCanvasesDetectsRemovedFrames(t *testing.T) {
+	cached := Entry{CanvasModified: map[string]string{
+		"frameA": "2026-01-01T00:00:00Z",
+		"frameB": "2026-01-01T00:00:00Z",
+	}}
+	current := map[string]string{
+		"frameA": "2026-01-01T00:00:00Z",
+	}
+
+	got := DeletedCanvases(cached, current)
+
+	if len(got) != 1 || got[0] != "frameB" {
+		t.Errorf("DeletedCanvases() = %v, want [frameB]", got)
+	}
+}
@@ -0,0 +1,102 @@
+// Package codegen はLayerInfoのツリーから実際のUIコードを生成するための
+// プラガブルなジェネレーターを提供します。
+package codegen
+
+// LayerInfo はmainパッケージのLayerInfoと同じ形状を持つ入力データです。
+// main側の型に依存させずにこのパッケージを独立させるため、ここで再定義しています。
+type LayerInfo struct {
+	ID                  string
+	Name                string
+	Type                string
+	ParentID            string
+	AbsoluteBoundingBox *AbsoluteBoundingBox
+	Styles              map[string]string
+	Constraints         *Constraints
+	Fill                *Fill
+	Stroke              *Stroke
+	TextStyle           *TextStyle
+}
+
+// Fill は解決済みの単色塗りつぶしです（最初のSOLID fillのみ反映します）。
+type Fill struct {
+	Hex     string // "#rrggbbaa"
+	Opacity float64
+}
+
+// Stroke は解決済みの単色ストロークです（最初のSOLID strokeのみ反映します）。
+type Stroke struct {
+	Hex    string // "#rrggbbaa"
+	Weight float64
+}
+
+// TextStyle はTEXTノードのタイポグラフィです。
+type TextStyle struct {
+	FontFamily   string
+	FontWeight   float64
+	FontSizePx   float64
+	LineHeightPx float64
+}
+
+// AbsoluteBoundingBox はノードの絶対的なバウンディングボックスを表します。
+type AbsoluteBoundingBox struct {
+	X      float64
+	Y      float64
+	Width  float64
+	Height float64
+}
+
+// Constraints はノードの制約を表します。
+type Constraints struct {
+	Vertical   string
+	Horizontal string
+}
+
+// Generator はLayerInfoのフラットなリストからUIコードを生成します。
+// ツリー構造はParentIDで表現されているため、各実装が必要に応じて
+// 子ノードを再構築します。
+type Generator interface {
+	// Generate はlayersからコード文字列を生成します。
+	Generate(layers []LayerInfo) (string, error)
+	// Name はCLIの --target フラグで指定する識別子です（例: "html", "flutter"）。
+	Name() string
+}
+
+// ByParent はlayersをParentIDごとにグルーピングし、ルート（ParentID == ""）の
+// 順序を保ったまま子ノードの一覧を引けるようにします。
+func ByParent(layers []LayerInfo) map[string][]LayerInfo {
+	children := make(map[string][]LayerInfo)
+	for _, l := range layers {
+		children[l.ParentID] = append(children[l.ParentID], l)
+	}
+	return children
+}
+
+// relativeOrigin はboxの絶対座標を、parentが与えられていればそのparentからの
+// 相対座標に変換します。各ノードは自身の絶対配置コンテナ（CSSのposition: absolute、
+// Flutterのネストされたstack）として描画されるため、親の原点を引かないとネストが
+// 深くなるほど位置がずれます。parentがnil（トップレベルなど親に絶対位置が無い場合）
+// はboxの絶対座標をそのまま返します。
+func relativeOrigin(box, parent *AbsoluteBoundingBox) (x, y float64) {
+	if parent == nil {
+		return box.X, box.Y
+	}
+	return box.X - parent.X, box.Y - parent.Y
+}
+
+// registry は利用可能なGeneratorを --target 名で引けるようにします。
+var registry = map[string]func() Generator{}
+
+// Register はGeneratorのコンストラクタをtarget名に紐づけて登録します。
+// 各ジェネレーターの init() から呼び出すことを想定しています。
+func Register(target string, ctor func() Generator) {
+	registry[target] = ctor
+}
+
+// Lookup はtarget名に対応するGeneratorを返します。未登録の場合はokがfalseになります。
+func Lookup(target string) (Generator, bool) {
+	ctor, ok := registry[target]
+	if !ok {
+		return nil, false
+	}
+	return ctor(), true
+}
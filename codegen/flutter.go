@@ -0,0 +1,156 @@
+package codegen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	Register("flutter", func() Generator { return &FlutterGenerator{} })
+}
+
+// FlutterGenerator はLayerInfoのツリーをStack+Positionedのウィジェットツリーに変換します。
+type FlutterGenerator struct{}
+
+// Name はCLIの --target に渡す識別子を返します。
+func (g *FlutterGenerator) Name() string { return "flutter" }
+
+// Generate はlayersからwidgetを返す単一のDartビルダー関数を生成します。
+func (g *FlutterGenerator) Generate(layers []LayerInfo) (string, error) {
+	children := ByParent(layers)
+
+	var body strings.Builder
+	body.WriteString("import 'package:flutter/widgets.dart';\n\n")
+	body.WriteString("Widget buildGeneratedLayout() {\n")
+	body.WriteString("  return Stack(\n    children: [\n")
+	for _, root := range children[""] {
+		writeWidget(root, children, &body, 3, nil)
+	}
+	body.WriteString("    ],\n  );\n}\n")
+	return body.String(), nil
+}
+
+func writeWidget(node LayerInfo, children map[string][]LayerInfo, out *strings.Builder, depth int, parentBox *AbsoluteBoundingBox) {
+	indent := strings.Repeat("  ", depth)
+
+	if node.AbsoluteBoundingBox == nil {
+		// 位置情報を持たないノードはPositionedでは包めないため、そのまま展開します。
+		// このノード自身に絶対位置が無いため、子にとっての親原点も無いものとして扱います。
+		writeChildren(node, children, out, depth, nil)
+		return
+	}
+
+	box := node.AbsoluteBoundingBox
+	relX, relY := relativeOrigin(box, parentBox)
+	out.WriteString(fmt.Sprintf("%sPositioned(\n", indent))
+	out.WriteString(fmt.Sprintf("%s  left: %g,\n", indent, relX))
+	out.WriteString(fmt.Sprintf("%s  top: %g,\n", indent, relY))
+	out.WriteString(fmt.Sprintf("%s  width: %g,\n", indent, box.Width))
+	out.WriteString(fmt.Sprintf("%s  height: %g,\n", indent, box.Height))
+	out.WriteString(fmt.Sprintf("%s  child: Container(\n", indent))
+	out.WriteString(fmt.Sprintf("%s    // %s (%s)\n", indent, node.Name, node.ID))
+	if decoration := writeDecoration(node); decoration != "" {
+		out.WriteString(fmt.Sprintf("%s    decoration: %s,\n", indent, decoration))
+	}
+
+	childIndent := depth + 2
+	closeChild := func() {
+		out.WriteString(fmt.Sprintf("%s  ),\n", indent))
+		out.WriteString(fmt.Sprintf("%s),\n", indent))
+	}
+
+	if node.TextStyle != nil {
+		out.WriteString(fmt.Sprintf("%s    child: DefaultTextStyle(\n", indent))
+		out.WriteString(fmt.Sprintf("%s      style: %s,\n", indent, writeTextStyle(node.TextStyle)))
+		childIndent = depth + 3
+		indent = indent + "  "
+	}
+
+	kids := children[node.ID]
+	if len(kids) == 0 {
+		if node.TextStyle != nil {
+			out.WriteString(fmt.Sprintf("%s  child: SizedBox.shrink(),\n", indent))
+			out.WriteString(fmt.Sprintf("%s),\n", indent))
+		}
+		closeChild()
+		return
+	}
+
+	out.WriteString(fmt.Sprintf("%s    child: Stack(\n      %schildren: [\n", indent, indent))
+	for _, child := range kids {
+		writeWidget(child, children, out, childIndent, box)
+	}
+	out.WriteString(fmt.Sprintf("%s  %s],\n", indent, indent))
+	out.WriteString(fmt.Sprintf("%s  %s),\n", indent, indent))
+	if node.TextStyle != nil {
+		out.WriteString(fmt.Sprintf("%s),\n", indent))
+	}
+	closeChild()
+}
+
+// writeDecoration はFill/StrokeからBoxDecoration式を組み立てます。どちらも無い場合は空文字を返します。
+func writeDecoration(node LayerInfo) string {
+	if node.Fill == nil && node.Stroke == nil {
+		return ""
+	}
+	var parts []string
+	if node.Fill != nil {
+		parts = append(parts, fmt.Sprintf("color: Color(%s)", flutterColor(node.Fill.Hex, node.Fill.Opacity)))
+	}
+	if node.Stroke != nil {
+		parts = append(parts, fmt.Sprintf("border: Border.all(color: Color(%s), width: %g)", flutterColor(node.Stroke.Hex, 1), node.Stroke.Weight))
+	}
+	return fmt.Sprintf("BoxDecoration(%s)", strings.Join(parts, ", "))
+}
+
+// writeTextStyle はTextStyleをDartのTextStyle(...)式に変換します。
+func writeTextStyle(ts *TextStyle) string {
+	var parts []string
+	if ts.FontFamily != "" {
+		parts = append(parts, fmt.Sprintf("fontFamily: '%s'", ts.FontFamily))
+	}
+	if ts.FontWeight != 0 {
+		parts = append(parts, fmt.Sprintf("fontWeight: %s", dartFontWeight(ts.FontWeight)))
+	}
+	if ts.FontSizePx != 0 {
+		parts = append(parts, fmt.Sprintf("fontSize: %g", ts.FontSizePx))
+	}
+	if ts.LineHeightPx != 0 && ts.FontSizePx != 0 {
+		parts = append(parts, fmt.Sprintf("height: %g", ts.LineHeightPx/ts.FontSizePx))
+	}
+	return fmt.Sprintf("TextStyle(%s)", strings.Join(parts, ", "))
+}
+
+// flutterColor は"#rrggbbaa"形式とopacityをFlutterのColor()に渡す0xAARRGGBB形式へ変換します。
+func flutterColor(hex string, opacity float64) string {
+	h := strings.TrimPrefix(hex, "#")
+	if len(h) != 8 {
+		return "0xFFFFFFFF"
+	}
+	rr, gg, bb, aaHex := h[0:2], h[2:4], h[4:6], h[6:8]
+	alpha, err := strconv.ParseInt(aaHex, 16, 32)
+	if err != nil {
+		alpha = 255
+	}
+	alpha = int64(float64(alpha) * opacity)
+	return fmt.Sprintf("0x%02X%s%s%s", alpha, strings.ToUpper(rr), strings.ToUpper(gg), strings.ToUpper(bb))
+}
+
+// dartFontWeight はFigmaの100〜900のfontWeightをFlutterのFontWeight.wNNNへ丸めます。
+func dartFontWeight(weight float64) string {
+	w := int(weight/100) * 100
+	if w < 100 {
+		w = 100
+	}
+	if w > 900 {
+		w = 900
+	}
+	return fmt.Sprintf("FontWeight.w%d", w)
+}
+
+func writeChildren(node LayerInfo, children map[string][]LayerInfo, out *strings.Builder, depth int, parentBox *AbsoluteBoundingBox) {
+	for _, child := range children[node.ID] {
+		writeWidget(child, children, out, depth, parentBox)
+	}
+}
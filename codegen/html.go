@@ -0,0 +1,154 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+func init() {
+	Register("html", func() Generator { return &HTMLGenerator{} })
+}
+
+// HTMLGenerator はLayerInfoのツリーを絶対配置のdivとCSSに変換します。
+type HTMLGenerator struct{}
+
+// Name はCLIの --target に渡す識別子を返します。
+func (g *HTMLGenerator) Name() string { return "html" }
+
+// Generate はlayersから単一のHTMLドキュメント（インラインの<style>タグ付き）を生成します。
+func (g *HTMLGenerator) Generate(layers []LayerInfo) (string, error) {
+	children := ByParent(layers)
+
+	var body strings.Builder
+	var style strings.Builder
+
+	for _, root := range children[""] {
+		renderNode(root, children, &body, &style, 0, nil)
+	}
+
+	var out strings.Builder
+	out.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<style>\n")
+	out.WriteString(style.String())
+	out.WriteString("</style>\n</head>\n<body>\n")
+	out.WriteString(body.String())
+	out.WriteString("</body>\n</html>\n")
+	return out.String(), nil
+}
+
+func renderNode(node LayerInfo, children map[string][]LayerInfo, body, style *strings.Builder, depth int, parentBox *AbsoluteBoundingBox) {
+	indent := strings.Repeat("  ", depth)
+	class := cssClassName(node)
+
+	style.WriteString(fmt.Sprintf(".%s {\n", class))
+	style.WriteString("  position: absolute;\n")
+	if node.AbsoluteBoundingBox != nil {
+		writePositionRules(node, parentBox, style)
+		style.WriteString(fmt.Sprintf("  width: %gpx;\n", node.AbsoluteBoundingBox.Width))
+		style.WriteString(fmt.Sprintf("  height: %gpx;\n", node.AbsoluteBoundingBox.Height))
+	}
+	writeStyleRules(node, style)
+	style.WriteString("}\n\n")
+
+	body.WriteString(fmt.Sprintf("%s<div class=\"%s\" data-layer-id=\"%s\" data-layer-name=\"%s\">\n", indent, class, node.ID, node.Name))
+	for _, child := range children[node.ID] {
+		renderNode(child, children, body, style, depth+1, node.AbsoluteBoundingBox)
+	}
+	body.WriteString(fmt.Sprintf("%s</div>\n", indent))
+}
+
+// writePositionRules はFigmaのConstraintsをCSSのtop/right/bottom/leftに変換します。
+// デフォルト（MIN/MIN相当）はtop/leftでの絶対配置です。各ノードはCSSの
+// position: absoluteとして描画されるため、座標はparentBox（親の絶対バウンディング
+// ボックス）からの相対値に変換します。parentBoxがnil（トップレベルノード）の場合は
+// 絶対座標をそのまま使います。
+func writePositionRules(node LayerInfo, parentBox *AbsoluteBoundingBox, style *strings.Builder) {
+	box := node.AbsoluteBoundingBox
+	horizontal := "LEFT"
+	vertical := "TOP"
+	if node.Constraints != nil {
+		if node.Constraints.Horizontal != "" {
+			horizontal = node.Constraints.Horizontal
+		}
+		if node.Constraints.Vertical != "" {
+			vertical = node.Constraints.Vertical
+		}
+	}
+
+	relX, relY := relativeOrigin(box, parentBox)
+
+	switch horizontal {
+	case "RIGHT":
+		if parentBox != nil {
+			style.WriteString(fmt.Sprintf("  right: %gpx;\n", parentBox.Width-relX-box.Width))
+		} else {
+			style.WriteString(fmt.Sprintf("  left: %gpx;\n", relX))
+		}
+	case "CENTER", "SCALE":
+		style.WriteString(fmt.Sprintf("  left: %gpx;\n", relX))
+	default: // LEFT, STRETCH, それ以外
+		style.WriteString(fmt.Sprintf("  left: %gpx;\n", relX))
+	}
+
+	switch vertical {
+	case "BOTTOM":
+		if parentBox != nil {
+			style.WriteString(fmt.Sprintf("  bottom: %gpx;\n", parentBox.Height-relY-box.Height))
+		} else {
+			style.WriteString(fmt.Sprintf("  top: %gpx;\n", relY))
+		}
+	case "CENTER", "SCALE":
+		style.WriteString(fmt.Sprintf("  top: %gpx;\n", relY))
+	default: // TOP, STRETCH, それ以外
+		style.WriteString(fmt.Sprintf("  top: %gpx;\n", relY))
+	}
+}
+
+// writeStyleRules はFills/Strokes/TextStyleをbackground-color/border/font-*のCSSに変換します。
+func writeStyleRules(node LayerInfo, style *strings.Builder) {
+	if node.Fill != nil {
+		style.WriteString(fmt.Sprintf("  background-color: %s;\n", node.Fill.Hex))
+		if node.Fill.Opacity != 1 {
+			style.WriteString(fmt.Sprintf("  opacity: %g;\n", node.Fill.Opacity))
+		}
+	}
+	if node.Stroke != nil {
+		style.WriteString(fmt.Sprintf("  border: %gpx solid %s;\n", node.Stroke.Weight, node.Stroke.Hex))
+	}
+	if node.TextStyle != nil {
+		if node.TextStyle.FontFamily != "" {
+			style.WriteString(fmt.Sprintf("  font-family: %s;\n", node.TextStyle.FontFamily))
+		}
+		if node.TextStyle.FontWeight != 0 {
+			style.WriteString(fmt.Sprintf("  font-weight: %g;\n", node.TextStyle.FontWeight))
+		}
+		if node.TextStyle.FontSizePx != 0 {
+			style.WriteString(fmt.Sprintf("  font-size: %gpx;\n", node.TextStyle.FontSizePx))
+		}
+		if node.TextStyle.LineHeightPx != 0 {
+			style.WriteString(fmt.Sprintf("  line-height: %gpx;\n", node.TextStyle.LineHeightPx))
+		}
+	}
+}
+
+// cssClassName はレイヤー名とIDからCSSクラス名として安全な文字列を作ります。
+func cssClassName(node LayerInfo) string {
+	safe := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '-'
+		}
+	}, node.Name)
+	safe = strings.Trim(safe, "-")
+	if safe == "" {
+		safe = "layer"
+	}
+	id := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '-'
+	}, node.ID)
+	return fmt.Sprintf("%s-%s", strings.ToLower(safe), id)
+}
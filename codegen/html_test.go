@@ -0,0 +1,74 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCssClassNameSanitizesAndFallsBack(t *testing.T) {
+	cases := []struct {
+		name string
+		id   string
+		want string
+	}{
+		{name: "Primary Button", id: "1:23", want: "primary-button-1-23"},
+		{name: "", id: "1:23", want: "layer-1-23"},
+		{name: "!!!", id: "1:23", want: "layer-1-23"},
+	}
+	for _, c := range cases {
+		got := cssClassName(LayerInfo{Name: c.name, ID: c.id})
+		if got != c.want {
+			t.Errorf("cssClassName(%q, %q) = %q, want %q", c.name, c.id, got, c.want)
+		}
+	}
+}
+
+func TestRenderNodePositionsChildrenRelativeToParent(t *testing.T) {
+	layers := []LayerInfo{
+		{ID: "root", Name: "Root", AbsoluteBoundingBox: &AbsoluteBoundingBox{X: 100, Y: 100, Width: 200, Height: 200}},
+		{ID: "child", Name: "Child", ParentID: "root", AbsoluteBoundingBox: &AbsoluteBoundingBox{X: 150, Y: 150, Width: 50, Height: 50}},
+	}
+	children := ByParent(layers)
+
+	var body, style strings.Builder
+	for _, root := range children[""] {
+		renderNode(root, children, &body, &style, 0, nil)
+	}
+	out := style.String()
+
+	if !strings.Contains(out, "left: 50px;") || !strings.Contains(out, "top: 50px;") {
+		t.Errorf("renderNode() did not position child relative to parent, got:\n%s", out)
+	}
+	if strings.Contains(out, "left: 150px;") || strings.Contains(out, "top: 150px;") {
+		t.Errorf("renderNode() used document-absolute coordinates for nested child, got:\n%s", out)
+	}
+}
+
+func TestWriteStyleRulesEmitsFillStrokeAndFont(t *testing.T) {
+	node := LayerInfo{
+		Fill:   &Fill{Hex: "#ff0000ff", Opacity: 1},
+		Stroke: &Stroke{Hex: "#00ff00ff", Weight: 2},
+		TextStyle: &TextStyle{
+			FontFamily:   "Inter",
+			FontWeight:   700,
+			FontSizePx:   16,
+			LineHeightPx: 24,
+		},
+	}
+	var style strings.Builder
+	writeStyleRules(node, &style)
+	out := style.String()
+
+	for _, want := range []string{
+		"background-color: #ff0000ff;",
+		"border: 2px solid #00ff00ff;",
+		"font-family: Inter;",
+		"font-weight: 700;",
+		"font-size: 16px;",
+		"line-height: 24px;",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("writeStyleRules output missing %q, got:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// Package figmaclient はFigma APIへのHTTP呼び出しを、ホストごとのレート制限・
+// 429/5xx時の指数バックオフ再試行・タイムアウト・構造化ログでラップします。
+package figmaclient
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const defaultRatePerSecond = 2.0
+
+// Option はClientの構築時設定を変更する関数です。
+type Option func(*Client)
+
+// WithRate はホストあたりの秒間リクエスト数（トークンバケットの補充レート）を設定します。
+// レート制限はホストごとに独立したバケットで管理されるため、例えばapi.figma.comと
+// S3/CDN上のアセットURLを同時に叩いても互いのレート予算を食い合いません。
+func WithRate(ratePerSecond float64) Option {
+	return func(c *Client) { c.ratePerSecond = ratePerSecond }
+}
+
+// WithLogger は構造化ログの出力先を設定します。未設定時は slog.Default() を使います。
+func WithLogger(logger *slog.Logger) Option {
+	return func(c *Client) { c.logger = logger }
+}
+
+// WithTimeout はリクエストごとのタイムアウト（接続からレスポンス受信まで）を設定します。
+func WithTimeout(d time.Duration) Option {
+	return func(c *Client) { c.httpClient.Timeout = d }
+}
+
+// WithMaxRetries は429/5xx応答時の最大再試行回数を設定します。
+func WithMaxRetries(n int) Option {
+	return func(c *Client) { c.maxRetries = n }
+}
+
+// Client はFigma API呼び出し用のレート制限・再試行付きHTTPクライアントです。
+// レート制限はホストごとに個別のトークンバケットを持ちます（per-host）。
+type Client struct {
+	httpClient    *http.Client
+	ratePerSecond float64
+	limiters      map[string]*rateLimiter
+	limitersMu    sync.Mutex
+	logger        *slog.Logger
+	maxRetries    int
+}
+
+// New はデフォルト設定（ホストあたり2req/s、最大5回再試行、15秒タイムアウト）のClientを作ります。
+func New(opts ...Option) *Client {
+	c := &Client{
+		httpClient:    &http.Client{Timeout: 15 * time.Second},
+		ratePerSecond: defaultRatePerSecond,
+		limiters:      make(map[string]*rateLimiter),
+		logger:        slog.Default(),
+		maxRetries:    5,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// limiterFor はhostに対応するrateLimiterを返し、無ければ作成します。
+func (c *Client) limiterFor(host string) *rateLimiter {
+	c.limitersMu.Lock()
+	defer c.limitersMu.Unlock()
+	limiter, ok := c.limiters[host]
+	if !ok {
+		limiter = newRateLimiter(c.ratePerSecond)
+		c.limiters[host] = limiter
+	}
+	return limiter
+}
+
+// Do はreqをレート制限・再試行付きで実行します。429またはサーバーエラー(5xx)の場合、
+// Retry-Afterヘッダー（あれば優先）かジッター付き指数バックオフで待ってから再試行します。
+func (c *Client) Do(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	limiter := c.limiterFor(req.URL.Host)
+
+	var lastErr error
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if err := limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		c.logger.Debug("figma APIリクエスト", "method", req.Method, "url", req.URL.String(), "attempt", attempt)
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			lastErr = err
+			if !shouldRetryError(err) || attempt == c.maxRetries {
+				c.logger.Error("figma APIリクエストが最終的に失敗しました", "url", req.URL.String(), "error", err, "attempt", attempt)
+				return nil, err
+			}
+			c.logger.Info("figma APIリクエストを再試行します", "error", err, "attempt", attempt)
+			if waitErr := sleepContext(ctx, backoffDuration(attempt, 0)); waitErr != nil {
+				return nil, waitErr
+			}
+			continue
+		}
+
+		if !isRetryableStatus(resp.StatusCode) || attempt == c.maxRetries {
+			if isRetryableStatus(resp.StatusCode) {
+				c.logger.Error("figma APIが最大再試行回数に達しても失敗ステータスを返しました",
+					"url", req.URL.String(), "status", resp.StatusCode, "attempt", attempt)
+			}
+			return resp, nil
+		}
+
+		retryAfter := retryAfterDuration(resp)
+		resp.Body.Close()
+		c.logger.Info("figma APIがリトライ可能なステータスを返しました",
+			"status", resp.StatusCode, "attempt", attempt, "retry_after", retryAfter)
+		if waitErr := sleepContext(ctx, backoffDuration(attempt, retryAfter)); waitErr != nil {
+			return nil, waitErr
+		}
+	}
+
+	if lastErr != nil {
+		c.logger.Error("figma APIリクエストが最終的に失敗しました", "url", req.URL.String(), "error", lastErr)
+	}
+	return nil, lastErr
+}
+
+// Get はurlに対するGETリクエストをDo経由で実行する簡易ヘルパーです。
+func (c *Client) Get(url string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return c.Do(req)
+}
+
+func isRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status >= 500
+}
+
+// shouldRetryError はネットワークレベルのエラー（タイムアウトなど）を再試行対象とみなすかを判定します。
+func shouldRetryError(err error) bool {
+	if err == nil {
+		return false
+	}
+	// コンテキストのキャンセル/タイムアウトは呼び出し元が待つのをやめたということなので、
+	// 再試行しても意味がないため除外します。
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	return true
+}
+
+// retryAfterDuration はレスポンスのRetry-Afterヘッダーを解釈します。無ければ0を返します。
+func retryAfterDuration(resp *http.Response) time.Duration {
+	header := resp.Header.Get("Retry-After")
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if when, err := http.ParseTime(header); err == nil {
+		return time.Until(when)
+	}
+	return 0
+}
+
+// backoffDuration はattempt回目の待機時間を、Retry-Afterがあればそれを優先し、
+// 無ければジッター付きの指数バックオフ（base 500ms、上限30秒）で計算します。
+func backoffDuration(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+
+	base := 500 * time.Millisecond
+	maxBackoff := 30 * time.Second
+	backoff := base << attempt
+	if backoff > maxBackoff || backoff <= 0 {
+		backoff = maxBackoff
+	}
+	jitter := time.Duration(rand.Int63n(int64(backoff) / 2))
+	return backoff/2 + jitter
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
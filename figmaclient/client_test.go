@@ -0,0 +1,69 @@
+package figmaclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestBackoffDurationPrefersRetryAfter(t *testing.T) {
+	got := backoffDuration(0, 3*time.Second)
+	if got != 3*time.Second {
+		t.Errorf("backoffDuration with Retry-After = %v, want %v", got, 3*time.Second)
+	}
+}
+
+func TestBackoffDurationGrowsAndCaps(t *testing.T) {
+	prevMax := time.Duration(0)
+	for attempt := 0; attempt < 10; attempt++ {
+		d := backoffDuration(attempt, 0)
+		if d <= 0 {
+			t.Fatalf("backoffDuration(%d, 0) = %v, want > 0", attempt, d)
+		}
+		if d > 30*time.Second {
+			t.Errorf("backoffDuration(%d, 0) = %v, want <= 30s cap", attempt, d)
+		}
+		// Until the cap applies, each attempt's range [backoff/2, backoff) starts
+		// where the previous attempt's range ends, so the delay strictly grows.
+		if uncapped := 500 * time.Millisecond << attempt; uncapped <= 30*time.Second && d <= prevMax {
+			t.Errorf("backoffDuration(%d, 0) = %v, want > previous attempt's %v", attempt, d, prevMax)
+		}
+		prevMax = d
+	}
+}
+
+func TestShouldRetryErrorExcludesContextCancellationAndTimeout(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"canceled", context.Canceled, false},
+		{"deadline exceeded", context.DeadlineExceeded, false},
+		{"wrapped canceled", fmt.Errorf("do: %w", context.Canceled), false},
+		{"other network error", errors.New("connection reset by peer"), true},
+	}
+	for _, c := range cases {
+		if got := shouldRetryError(c.err); got != c.want {
+			t.Errorf("shouldRetryError(%v) = %v, want %v", c.err, got, c.want)
+		}
+	}
+}
+
+func TestIsRetryableStatus(t *testing.T) {
+	cases := map[int]bool{
+		200: false,
+		404: false,
+		429: true,
+		500: true,
+		503: true,
+	}
+	for status, want := range cases {
+		if got := isRetryableStatus(status); got != want {
+			t.Errorf("isRetryableStatus(%d) = %v, want %v", status, got, want)
+		}
+	}
+}
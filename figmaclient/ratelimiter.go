@@ -0,0 +1,68 @@
+package figmaclient
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// rateLimiter はシンプルなトークンバケットです。1秒あたりratePerSecond個の
+// トークンが補充され、Waitはトークンが使えるまでブロックします。
+type rateLimiter struct {
+	mu         sync.Mutex
+	tokens     float64
+	maxTokens  float64
+	ratePerSec float64
+	lastRefill time.Time
+}
+
+func newRateLimiter(ratePerSec float64) *rateLimiter {
+	// バケット容量が1未満だとトークンが1個に達せずWaitが永久に待つため、最低1にします。
+	burst := ratePerSec
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimiter{
+		tokens:     burst,
+		maxTokens:  burst,
+		ratePerSec: ratePerSec,
+		lastRefill: time.Now(),
+	}
+}
+
+// Wait はトークンを1個消費できるまで待機します。ctxがキャンセルされた場合は
+// ctx.Err()を返します。
+func (r *rateLimiter) Wait(ctx context.Context) error {
+	for {
+		r.mu.Lock()
+		now := time.Now()
+		elapsed := now.Sub(r.lastRefill).Seconds()
+		r.tokens = minFloat(r.maxTokens, r.tokens+elapsed*r.ratePerSec)
+		r.lastRefill = now
+
+		if r.tokens >= 1 {
+			r.tokens--
+			r.mu.Unlock()
+			return nil
+		}
+
+		// 次にトークンが1個補充されるまでの待ち時間
+		wait := time.Duration((1 - r.tokens) / r.ratePerSec * float64(time.Second))
+		r.mu.Unlock()
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func minFloat(a, b float64) float64 {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,41 @@
+package figmaclient
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterWaitBlocksUntilTokensRefill(t *testing.T) {
+	limiter := newRateLimiter(100) // 100 req/s, so a refill is fast but measurable
+
+	// Drain the initial burst of tokens.
+	for i := 0; i < 100; i++ {
+		if err := limiter.Wait(context.Background()); err != nil {
+			t.Fatalf("Wait() on burst token %d returned error: %v", i, err)
+		}
+	}
+
+	start := time.Now()
+	if err := limiter.Wait(context.Background()); err != nil {
+		t.Fatalf("Wait() after bucket drained returned error: %v", err)
+	}
+	// At 100 tokens/s a drained bucket needs about 10ms to refill one token.
+	if elapsed := time.Since(start); elapsed < time.Millisecond {
+		t.Errorf("Wait() after bucket drained returned immediately, want to block for a refill")
+	}
+}
+
+func TestRateLimiterWaitRespectsContextCancellation(t *testing.T) {
+	limiter := newRateLimiter(0.001) // effectively never refills within the test
+	for limiter.tokens >= 1 {
+		limiter.tokens--
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := limiter.Wait(ctx); err == nil {
+		t.Error("Wait() with an exhausted bucket and a cancelled context should return an error")
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/nekoPhysics/figma_to_codev1/auth"
+)
+
+// runLogin は `login` サブコマンドを処理し、OAuth2フローで取得した資格情報を保存します。
+func runLogin(args []string) {
+	fs := flag.NewFlagSet("login", flag.ExitOnError)
+	fs.Parse(args)
+
+	opts := oauthOptionsFromEnv()
+	if opts.ClientID == "" || opts.ClientSecret == "" {
+		fmt.Println("FIGMA_OAUTH_CLIENT_ID と FIGMA_OAUTH_CLIENT_SECRET 環境変数を設定してください")
+		return
+	}
+
+	fmt.Println("ブラウザでFigmaの認可画面を開いています...")
+	creds, err := auth.Login(context.Background(), opts)
+	if err != nil {
+		fmt.Printf("ログインに失敗しました: %v\n", err)
+		return
+	}
+
+	if err := auth.SaveCredentials(creds); err != nil {
+		fmt.Printf("資格情報の保存に失敗しました: %v\n", err)
+		return
+	}
+
+	path, _ := auth.CredentialsPath()
+	fmt.Printf("ログインが完了しました。資格情報を %s に保存しました。\n", path)
+}
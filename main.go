@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
+	"flag"
 	"fmt"
 	"io"
 	"net/http"
@@ -9,6 +11,11 @@ import (
 	"os"
 	"regexp"
 	"strings"
+
+	"github.com/nekoPhysics/figma_to_codev1/auth"
+	"github.com/nekoPhysics/figma_to_codev1/cache"
+	"github.com/nekoPhysics/figma_to_codev1/codegen"
+	"github.com/nekoPhysics/figma_to_codev1/figmaclient"
 )
 
 // LayerInfo は抽出したいレイヤー情報を表します
@@ -20,6 +27,11 @@ type LayerInfo struct {
 	AbsoluteBoundingBox *AbsoluteBoundingBox `json:"absoluteBoundingBox,omitempty"`
 	Styles              map[string]string    `json:"styles,omitempty"`
 	Constraints         *Constraints         `json:"constraints,omitempty"`
+	AssetPath           string               `json:"asset_path,omitempty"`
+	Fills               []FigmaPaint         `json:"fills,omitempty"`
+	Strokes             []FigmaPaint         `json:"strokes,omitempty"`
+	StrokeWeight        float64              `json:"strokeWeight,omitempty"`
+	TextStyle           *FigmaTypeStyle      `json:"textStyle,omitempty"`
 }
 
 // AbsoluteBoundingBox はノードの絶対的なバウンディングボックスを表します
@@ -38,9 +50,18 @@ type Constraints struct {
 
 // FigmaFile APIレスポンスをマッピングする構造体
 type FigmaFile struct {
-	Name       string                   `json:"name"`
-	Document   FigmaDocument            `json:"document"`
+	Name       string                    `json:"name"`
+	Document   FigmaDocument             `json:"document"`
 	Components map[string]FigmaComponent `json:"components"`
+	Styles     map[string]FigmaStyleMeta `json:"styles"`
+}
+
+// FigmaStyleMeta はFigmaFileの"styles"マップに含まれるスタイルのメタ情報です。
+type FigmaStyleMeta struct {
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	StyleType   string `json:"styleType"`
+	Description string `json:"description,omitempty"`
 }
 
 type FigmaDocument struct {
@@ -54,30 +75,101 @@ type FigmaNode struct {
 	ID                  string               `json:"id"`
 	Name                string               `json:"name"`
 	Type                string               `json:"type"`
+	LastModified        string               `json:"lastModified,omitempty"`
 	AbsoluteBoundingBox *AbsoluteBoundingBox `json:"absoluteBoundingBox,omitempty"`
 	Styles              map[string]string    `json:"styles,omitempty"`
 	Constraints         *Constraints         `json:"constraints,omitempty"`
 	Children            []FigmaNode          `json:"children,omitempty"`
+	Fills               []FigmaPaint         `json:"fills,omitempty"`
+	Strokes             []FigmaPaint         `json:"strokes,omitempty"`
+	StrokeWeight        float64              `json:"strokeWeight,omitempty"`
+	Effects             []FigmaEffect        `json:"effects,omitempty"`
+	Style               *FigmaTypeStyle      `json:"style,omitempty"`
 	// 必要に応じて他のフィールドを追加してください
 }
 
+// FigmaPaint はfills/strokesの1エントリです。トークン化ではSOLIDの色だけを解決します。
+type FigmaPaint struct {
+	Type    string      `json:"type"`
+	Color   *FigmaColor `json:"color,omitempty"`
+	Opacity *float64    `json:"opacity,omitempty"`
+}
+
+// FigmaColor は0〜1のRGBAカラーです。
+type FigmaColor struct {
+	R float64 `json:"r"`
+	G float64 `json:"g"`
+	B float64 `json:"b"`
+	A float64 `json:"a"`
+}
+
+// FigmaEffect はDROP_SHADOW/INNER_SHADOWなどのエフェクトです。
+type FigmaEffect struct {
+	Type   string       `json:"type"`
+	Color  *FigmaColor  `json:"color,omitempty"`
+	Offset *FigmaVector `json:"offset,omitempty"`
+	Radius float64      `json:"radius"`
+	Spread float64      `json:"spread,omitempty"`
+}
+
+// FigmaVector は2次元のオフセットです。
+type FigmaVector struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// FigmaTypeStyle はTEXTスタイルのタイポグラフィ情報です。
+type FigmaTypeStyle struct {
+	FontFamily   string  `json:"fontFamily"`
+	FontWeight   float64 `json:"fontWeight"`
+	FontSize     float64 `json:"fontSize"`
+	LineHeightPx float64 `json:"lineHeightPx"`
+}
+
 type FigmaComponent struct {
 	// 必要に応じてFigma APIのレスポンスに基づいてフィールドを定義してください
 }
 
 func main() {
-	if len(os.Args) < 3 {
-		fmt.Println("使用方法: go run main.go <Figma_URL> <ページ名>")
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		runServe(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "login" {
+		runLogin(os.Args[2:])
+		return
+	}
+
+	target := flag.String("target", "json", "出力形式: json, html, flutter")
+	fetchAssets := flag.Bool("fetch-assets", false, "IMAGE/VECTOR/塗りつぶし画像ノードのアセットをassets/にダウンロードする")
+	assetParallelism := flag.Int("asset-parallelism", 4, "アセットダウンロードの並列数")
+	assetFormat := flag.String("asset-format", "png", "ラスターノードの書き出し形式: png または jpg（VECTORは常にsvg）")
+	assetScale := flag.Int("asset-scale", 1, "ラスターノードの書き出し倍率: 1, 2, 3")
+	force := flag.Bool("force", false, "バージョンキャッシュを無視して常にフル同期する")
+	cacheDir := flag.String("cache-dir", ".figma-to-code-cache", "バージョン/レイヤーキャッシュの保存先ディレクトリ")
+	designTokens := flag.Bool("design-tokens", false, "ファイルのスタイル定義からtokens.json（W3C DTCG形式）を書き出す")
+	flag.Parse()
+	args := flag.Args()
+
+	if len(args) < 2 {
+		fmt.Println("使用方法: go run main.go [--target json|html|flutter] <Figma_URL> <ページ名>")
 		return
 	}
 
-	figmaURL := os.Args[1]
-	pageName := os.Args[2]
+	figmaURL := args[0]
+	pageName := args[1]
+
+	if *target != "json" {
+		if _, ok := codegen.Lookup(*target); !ok {
+			fmt.Printf("不明な --target です: %s\n", *target)
+			return
+		}
+	}
 
-	// 環境変数からAPIトークンを取得
-	figmaAPIToken := os.Getenv("FIGMA_API_TOKEN")
-	if figmaAPIToken == "" {
-		fmt.Println("FIGMA_API_TOKEN 環境変数が設定されていません")
+	// 認証方法を解決（FIGMA_API_TOKEN か、保存済みのOAuth資格情報）
+	tokenSource, err := auth.Resolve(context.Background(), oauthOptionsFromEnv())
+	if err != nil {
+		fmt.Println(err)
 		return
 	}
 
@@ -88,75 +180,254 @@ func main() {
 		return
 	}
 
-	// HTTPクライアントの作成
-	client := &http.Client{}
+	// レート制限・再試行付きHTTPクライアントの作成
+	client := figmaclient.New()
 
-	// Figma APIへのGETリクエストを作成
-	apiURL := fmt.Sprintf("https://api.figma.com/v1/files/%s", fileID)
-	req, err := http.NewRequest("GET", apiURL, nil)
+	// バージョンキャッシュの準備
+	cacheStore, err := cache.NewStore(*cacheDir)
 	if err != nil {
-		fmt.Printf("HTTPリクエストの作成に失敗しました: %v\n", err)
+		fmt.Printf("キャッシュの初期化に失敗しました: %v\n", err)
 		return
 	}
-
-	// 認証ヘッダーにFigma APIトークンを設定
-	req.Header.Set("X-Figma-Token", figmaAPIToken)
-
-	// リクエストを実行
-	resp, err := client.Do(req)
+	cachedEntry, hasCachedEntry, err := cacheStore.Load(fileID, pageName)
 	if err != nil {
-		fmt.Printf("HTTPリクエストの実行に失敗しました: %v\n", err)
+		fmt.Printf("キャッシュの読み込みに失敗しました: %v\n", err)
 		return
 	}
-	defer resp.Body.Close()
 
-	// レスポンスのステータスを確認
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		fmt.Printf("HTTPエラー: %s\nレスポンスボディ: %s\n", resp.Status, string(bodyBytes))
+	// versionsエンドポイントを先に叩き、未変更ならファイル全体の再取得を省略する。
+	// ただしコード生成・アセット取得・デザイントークンなどの後続処理は、
+	// キャッシュ済みレイヤーを使って引き続き実行する。
+	latestVersionID, err := fetchLatestVersionID(client, tokenSource, fileID)
+	if err != nil {
+		fmt.Printf("バージョン情報の取得に失敗しました: %v\n", err)
 		return
 	}
 
-	// JSONレスポンスをデコード
-	var figmaFileData FigmaFile
-	if err := json.NewDecoder(resp.Body).Decode(&figmaFileData); err != nil {
-		fmt.Printf("JSONレスポンスのデコードに失敗しました: %v\n", err)
-		return
+	var layers []LayerInfo
+	if !*force && hasCachedEntry && cachedEntry.VersionID == latestVersionID {
+		cachedLayers, err := loadCachedLayers(cachedEntry)
+		if err != nil {
+			fmt.Printf("キャッシュ済みレイヤー情報の読み込みに失敗しました: %v\n", err)
+			return
+		}
+		if cachedLayers != nil {
+			fmt.Println("変更はありませんでした（キャッシュされたバージョンと一致）。キャッシュ済みレイヤーを使用します。")
+			layers = cachedLayers
+		}
 	}
 
-	// 指定されたページを検索
-	var targetPage *FigmaNode
-	for _, child := range figmaFileData.Document.Children {
-		if child.Name == pageName && child.Type == "CANVAS" {
-			targetPage = &child
-			break
+	if layers == nil {
+		// Figmaファイルを取得
+		figmaFileData, err := fetchFigmaFile(client, tokenSource, fileID)
+		if err != nil {
+			fmt.Printf("Figmaファイルの取得に失敗しました: %v\n", err)
+			return
+		}
+
+		// 指定されたページを検索
+		targetPage := findPage(figmaFileData, pageName)
+		if targetPage == nil {
+			fmt.Printf("指定されたページ '%s' が見つかりませんでした。\n", pageName)
+			return
+		}
+
+		// レイヤー情報を抽出。キャッシュがあれば変更されたトップレベルフレームだけ再取得してマージする。
+		layers, err = extractLayersIncremental(client, tokenSource, fileID, *targetPage, cachedEntry, hasCachedEntry && !*force)
+		if err != nil {
+			fmt.Printf("レイヤー情報の抽出に失敗しました: %v\n", err)
+			return
+		}
+
+		if cachedLayersJSON, err := json.Marshal(layers); err == nil {
+			if err := cacheStore.Save(fileID, pageName, cache.Entry{
+				VersionID:      latestVersionID,
+				CanvasModified: canvasModifiedTimes(*targetPage),
+				Layers:         cachedLayersJSON,
+			}); err != nil {
+				fmt.Printf("キャッシュの保存に失敗しました: %v\n", err)
+			}
 		}
 	}
 
-	if targetPage == nil {
-		fmt.Printf("指定されたページ '%s' が見つかりませんでした。\n", pageName)
-		return
+	if *fetchAssets {
+		if err := fetchAndEmbedAssets(client, tokenSource, fileID, layers, "assets", *assetParallelism, *assetFormat, *assetScale); err != nil {
+			fmt.Printf("アセットの取得に失敗しました: %v\n", err)
+			return
+		}
 	}
 
-	// レイヤー情報を抽出
-	var layers []LayerInfo
-	extractLayers(*targetPage, "", &layers)
+	if *designTokens {
+		tokens, err := BuildDesignTokens(client, tokenSource, fileID)
+		if err != nil {
+			fmt.Printf("デザイントークンの生成に失敗しました: %v\n", err)
+			return
+		}
+		tokensJSON, err := json.MarshalIndent(tokens, "", "  ")
+		if err != nil {
+			fmt.Printf("デザイントークンのJSONシリアライズに失敗しました: %v\n", err)
+			return
+		}
+		if err := os.WriteFile("tokens.json", tokensJSON, 0644); err != nil {
+			fmt.Printf("tokens.json への書き込みに失敗しました: %v\n", err)
+			return
+		}
+		fmt.Println("デザイントークンが tokens.json に正常に書き込まれました。")
+	}
 
-	// レイヤー情報をJSONにシリアライズ
-	jsonData, err := json.MarshalIndent(layers, "", "  ")
+	if *target == "json" {
+		// レイヤー情報をJSONにシリアライズ
+		jsonData, err := json.MarshalIndent(layers, "", "  ")
+		if err != nil {
+			fmt.Printf("レイヤー情報のJSONシリアライズに失敗しました: %v\n", err)
+			return
+		}
+
+		outputFile := "layers.json"
+		if err := os.WriteFile(outputFile, jsonData, 0644); err != nil {
+			fmt.Printf("JSONファイルへの書き込みに失敗しました: %v\n", err)
+			return
+		}
+
+		fmt.Printf("レイヤー情報が %s に正常に書き込まれました。\n", outputFile)
+		return
+	}
+
+	// codegenジェネレーターでUIコードを生成
+	generator, _ := codegen.Lookup(*target)
+	code, err := generator.Generate(toCodegenLayers(layers))
 	if err != nil {
-		fmt.Printf("レイヤー情報のJSONシリアライズに失敗しました: %v\n", err)
+		fmt.Printf("コード生成に失敗しました: %v\n", err)
 		return
 	}
 
-	// JSONをファイルに出力
-	outputFile := "layers.json"
-	if err := os.WriteFile(outputFile, jsonData, 0644); err != nil {
-		fmt.Printf("JSONファイルへの書き込みに失敗しました: %v\n", err)
+	outputFile := codegenOutputFile(*target)
+	if err := os.WriteFile(outputFile, []byte(code), 0644); err != nil {
+		fmt.Printf("生成コードの書き込みに失敗しました: %v\n", err)
 		return
 	}
 
-	fmt.Printf("レイヤー情報が %s に正常に書き込まれました。\n", outputFile)
+	fmt.Printf("生成されたコードが %s に正常に書き込まれました。\n", outputFile)
+}
+
+// toCodegenLayers はmain側のLayerInfoをcodegenパッケージの型に変換します。
+func toCodegenLayers(layers []LayerInfo) []codegen.LayerInfo {
+	out := make([]codegen.LayerInfo, 0, len(layers))
+	for _, l := range layers {
+		cl := codegen.LayerInfo{
+			ID:       l.ID,
+			Name:     l.Name,
+			Type:     l.Type,
+			ParentID: l.ParentID,
+			Styles:   l.Styles,
+		}
+		if l.AbsoluteBoundingBox != nil {
+			cl.AbsoluteBoundingBox = &codegen.AbsoluteBoundingBox{
+				X:      l.AbsoluteBoundingBox.X,
+				Y:      l.AbsoluteBoundingBox.Y,
+				Width:  l.AbsoluteBoundingBox.Width,
+				Height: l.AbsoluteBoundingBox.Height,
+			}
+		}
+		if l.Constraints != nil {
+			cl.Constraints = &codegen.Constraints{
+				Vertical:   l.Constraints.Vertical,
+				Horizontal: l.Constraints.Horizontal,
+			}
+		}
+		if paint, ok := firstSolidPaint(l.Fills); ok {
+			opacity := 1.0
+			if paint.Opacity != nil {
+				opacity = *paint.Opacity
+			}
+			cl.Fill = &codegen.Fill{Hex: colorToHex(*paint.Color), Opacity: opacity}
+		}
+		if paint, ok := firstSolidPaint(l.Strokes); ok {
+			cl.Stroke = &codegen.Stroke{Hex: colorToHex(*paint.Color), Weight: l.StrokeWeight}
+		}
+		if l.TextStyle != nil {
+			cl.TextStyle = &codegen.TextStyle{
+				FontFamily:   l.TextStyle.FontFamily,
+				FontWeight:   l.TextStyle.FontWeight,
+				FontSizePx:   l.TextStyle.FontSize,
+				LineHeightPx: l.TextStyle.LineHeightPx,
+			}
+		}
+		out = append(out, cl)
+	}
+	return out
+}
+
+// firstSolidPaint はpaintsのうち最初のSOLID塗りつぶし/ストロークを返します。
+// グラデーションや画像フィルはcodegenでの色翻訳の対象外です。
+func firstSolidPaint(paints []FigmaPaint) (*FigmaPaint, bool) {
+	for i := range paints {
+		if paints[i].Type == "SOLID" && paints[i].Color != nil {
+			return &paints[i], true
+		}
+	}
+	return nil, false
+}
+
+// codegenOutputFile はターゲットごとの出力ファイル名を返します。
+func codegenOutputFile(target string) string {
+	switch target {
+	case "flutter":
+		return "generated_layout.dart"
+	default:
+		return fmt.Sprintf("generated.%s", target)
+	}
+}
+
+// fetchFigmaFile は GET /v1/files/{file_id} を呼び出し、FigmaFileとしてデコードします。
+// CLIの通常フローとserveサブコマンドの両方から共有して使われます。
+func fetchFigmaFile(client *figmaclient.Client, tokenSource *auth.TokenSource, fileID string) (*FigmaFile, error) {
+	apiURL := fmt.Sprintf("https://api.figma.com/v1/files/%s", fileID)
+	req, err := http.NewRequest("GET", apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("HTTPリクエストの作成に失敗しました: %w", err)
+	}
+	if err := tokenSource.SetHeader(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTPリクエストの実行に失敗しました: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("HTTPエラー: %s\nレスポンスボディ: %s", resp.Status, string(bodyBytes))
+	}
+
+	var figmaFileData FigmaFile
+	if err := json.NewDecoder(resp.Body).Decode(&figmaFileData); err != nil {
+		return nil, fmt.Errorf("JSONレスポンスのデコードに失敗しました: %w", err)
+	}
+	return &figmaFileData, nil
+}
+
+// oauthOptionsFromEnv はOAuthログイン/トークン更新に使うクライアント情報を環境変数から組み立てます。
+// FIGMA_OAUTH_CLIENT_ID / FIGMA_OAUTH_CLIENT_SECRET はFigmaの開発者コンソールでアプリを
+// 登録した際に発行される値です。
+func oauthOptionsFromEnv() auth.LoginOptions {
+	return auth.LoginOptions{
+		ClientID:     os.Getenv("FIGMA_OAUTH_CLIENT_ID"),
+		ClientSecret: os.Getenv("FIGMA_OAUTH_CLIENT_SECRET"),
+		Scopes:       []string{"file_read"},
+	}
+}
+
+// findPage はfigmaFileDataのトップレベルキャンバスから名前が一致するページを探します。
+func findPage(figmaFileData *FigmaFile, pageName string) *FigmaNode {
+	for i, child := range figmaFileData.Document.Children {
+		if child.Name == pageName && child.Type == "CANVAS" {
+			return &figmaFileData.Document.Children[i]
+		}
+	}
+	return nil
 }
 
 // extractFileID はFigmaのURLからファイルIDを抽出します。
@@ -216,9 +487,21 @@ func extractLayers(node FigmaNode, parentID string, layers *[]LayerInfo) {
 		layer.Constraints = node.Constraints
 	}
 
+	// fills/strokes/テキストスタイルはcodegenでの見た目の翻訳に使うため、そのまま引き継ぎます
+	if node.Fills != nil {
+		layer.Fills = node.Fills
+	}
+	if node.Strokes != nil {
+		layer.Strokes = node.Strokes
+		layer.StrokeWeight = node.StrokeWeight
+	}
+	if node.Style != nil {
+		layer.TextStyle = node.Style
+	}
+
 	*layers = append(*layers, layer)
 
 	for _, child := range node.Children {
 		extractLayers(child, node.ID, layers)
 	}
-}
\ No newline at end of file
+}
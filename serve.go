@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/nekoPhysics/figma_to_codev1/auth"
+	"github.com/nekoPhysics/figma_to_codev1/codegen"
+	"github.com/nekoPhysics/figma_to_codev1/figmaclient"
+	"github.com/nekoPhysics/figma_to_codev1/server"
+)
+
+// runServe は `serve` サブコマンドを処理し、選択UIをローカルHTTPサーバーとして起動します。
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "8080", "待ち受けるアドレス（例: 8080 または :8080）")
+	fs.Parse(args)
+
+	tokenSource, err := auth.Resolve(context.Background(), oauthOptionsFromEnv())
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	client := figmaclient.New()
+
+	fetchFile := func(fileID string) (*server.FigmaFile, error) {
+		figmaFileData, err := fetchFigmaFile(client, tokenSource, fileID)
+		if err != nil {
+			return nil, err
+		}
+		return toServerFigmaFile(figmaFileData), nil
+	}
+
+	extract := func(page server.FigmaNode) []server.LayerInfo {
+		var layers []LayerInfo
+		extractLayers(fromServerFigmaNode(page), "", &layers)
+		return toServerLayers(layers)
+	}
+
+	fetchImages := func(fileID string, nodeIDs []string) (map[string]string, error) {
+		return fetchImageURLs(client, tokenSource, fileID, nodeIDs, "png", 1)
+	}
+
+	generate := func(target string, layers []server.LayerInfo) (string, string, error) {
+		generator, ok := codegen.Lookup(target)
+		if !ok {
+			return "", "", fmt.Errorf("不明な target です: %s", target)
+		}
+		code, err := generator.Generate(toCodegenLayersFromServer(layers))
+		if err != nil {
+			return "", "", err
+		}
+		return codegenOutputFile(target), code, nil
+	}
+
+	srv := server.New(fetchFile, extract, fetchImages, generate)
+	if err := srv.ListenAndServe(server.NormalizeAddr(*addr)); err != nil {
+		fmt.Printf("サーバーの起動に失敗しました: %v\n", err)
+	}
+}
+
+func toServerFigmaFile(f *FigmaFile) *server.FigmaFile {
+	out := &server.FigmaFile{Name: f.Name}
+	for _, child := range f.Document.Children {
+		out.Document.Children = append(out.Document.Children, toServerFigmaNode(child))
+	}
+	return out
+}
+
+func toServerFigmaNode(n FigmaNode) server.FigmaNode {
+	out := server.FigmaNode{ID: n.ID, Name: n.Name, Type: n.Type}
+	if n.AbsoluteBoundingBox != nil {
+		out.AbsoluteBoundingBox = &server.AbsoluteBoundingBox{
+			X:      n.AbsoluteBoundingBox.X,
+			Y:      n.AbsoluteBoundingBox.Y,
+			Width:  n.AbsoluteBoundingBox.Width,
+			Height: n.AbsoluteBoundingBox.Height,
+		}
+	}
+	if n.Constraints != nil {
+		out.Constraints = &server.Constraints{Vertical: n.Constraints.Vertical, Horizontal: n.Constraints.Horizontal}
+	}
+	for _, child := range n.Children {
+		out.Children = append(out.Children, toServerFigmaNode(child))
+	}
+	return out
+}
+
+func fromServerFigmaNode(n server.FigmaNode) FigmaNode {
+	out := FigmaNode{ID: n.ID, Name: n.Name, Type: n.Type}
+	if n.AbsoluteBoundingBox != nil {
+		out.AbsoluteBoundingBox = &AbsoluteBoundingBox{
+			X:      n.AbsoluteBoundingBox.X,
+			Y:      n.AbsoluteBoundingBox.Y,
+			Width:  n.AbsoluteBoundingBox.Width,
+			Height: n.AbsoluteBoundingBox.Height,
+		}
+	}
+	if n.Constraints != nil {
+		out.Constraints = &Constraints{Vertical: n.Constraints.Vertical, Horizontal: n.Constraints.Horizontal}
+	}
+	for _, child := range n.Children {
+		out.Children = append(out.Children, fromServerFigmaNode(child))
+	}
+	return out
+}
+
+func toServerLayers(layers []LayerInfo) []server.LayerInfo {
+	out := make([]server.LayerInfo, 0, len(layers))
+	for _, l := range layers {
+		sl := server.LayerInfo{ID: l.ID, Name: l.Name, Type: l.Type, ParentID: l.ParentID}
+		if l.AbsoluteBoundingBox != nil {
+			sl.AbsoluteBoundingBox = &server.AbsoluteBoundingBox{
+				X:      l.AbsoluteBoundingBox.X,
+				Y:      l.AbsoluteBoundingBox.Y,
+				Width:  l.AbsoluteBoundingBox.Width,
+				Height: l.AbsoluteBoundingBox.Height,
+			}
+		}
+		if l.Constraints != nil {
+			sl.Constraints = &server.Constraints{Vertical: l.Constraints.Vertical, Horizontal: l.Constraints.Horizontal}
+		}
+		if paint, ok := firstSolidPaint(l.Fills); ok {
+			opacity := 1.0
+			if paint.Opacity != nil {
+				opacity = *paint.Opacity
+			}
+			sl.Fill = &server.Fill{Hex: colorToHex(*paint.Color), Opacity: opacity}
+		}
+		if paint, ok := firstSolidPaint(l.Strokes); ok {
+			sl.Stroke = &server.Stroke{Hex: colorToHex(*paint.Color), Weight: l.StrokeWeight}
+		}
+		if l.TextStyle != nil {
+			sl.TextStyle = &server.TextStyle{
+				FontFamily:   l.TextStyle.FontFamily,
+				FontWeight:   l.TextStyle.FontWeight,
+				FontSizePx:   l.TextStyle.FontSize,
+				LineHeightPx: l.TextStyle.LineHeightPx,
+			}
+		}
+		out = append(out, sl)
+	}
+	return out
+}
+
+// toCodegenLayersFromServer はserver.LayerInfoをcodegen.LayerInfoに変換します。
+// main.toCodegenLayersと同じ変換をserver経由のエクスポート用に行います。
+func toCodegenLayersFromServer(layers []server.LayerInfo) []codegen.LayerInfo {
+	out := make([]codegen.LayerInfo, 0, len(layers))
+	for _, l := range layers {
+		cl := codegen.LayerInfo{ID: l.ID, Name: l.Name, Type: l.Type, ParentID: l.ParentID}
+		if l.AbsoluteBoundingBox != nil {
+			cl.AbsoluteBoundingBox = &codegen.AbsoluteBoundingBox{
+				X:      l.AbsoluteBoundingBox.X,
+				Y:      l.AbsoluteBoundingBox.Y,
+				Width:  l.AbsoluteBoundingBox.Width,
+				Height: l.AbsoluteBoundingBox.Height,
+			}
+		}
+		if l.Constraints != nil {
+			cl.Constraints = &codegen.Constraints{Vertical: l.Constraints.Vertical, Horizontal: l.Constraints.Horizontal}
+		}
+		if l.Fill != nil {
+			cl.Fill = &codegen.Fill{Hex: l.Fill.Hex, Opacity: l.Fill.Opacity}
+		}
+		if l.Stroke != nil {
+			cl.Stroke = &codegen.Stroke{Hex: l.Stroke.Hex, Weight: l.Stroke.Weight}
+		}
+		if l.TextStyle != nil {
+			cl.TextStyle = &codegen.TextStyle{
+				FontFamily:   l.TextStyle.FontFamily,
+				FontWeight:   l.TextStyle.FontWeight,
+				FontSizePx:   l.TextStyle.FontSizePx,
+				LineHeightPx: l.TextStyle.LineHeightPx,
+			}
+		}
+		out = append(out, cl)
+	}
+	return out
+}
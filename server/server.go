@@ -0,0 +1,384 @@
+// Package server は選択UIをローカルHTTPサーバーとして提供します。
+// main側のFigma APIクライアント関数をHandlerFuncに注入して使うため、
+// LayerInfo/FigmaFileなどの型はこのパッケージにも最小限だけ複製しています。
+package server
+
+import (
+	"archive/zip"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+//go:embed static
+var staticFS embed.FS
+
+// FigmaFile はmain.FigmaFileの形状をHTTPレスポンス生成に必要な分だけ写したものです。
+type FigmaFile struct {
+	Name     string `json:"name"`
+	Document struct {
+		Children []FigmaNode `json:"children"`
+	} `json:"document"`
+}
+
+// FigmaNode はmain.FigmaNodeの形状を写したものです。
+type FigmaNode struct {
+	ID                  string               `json:"id"`
+	Name                string               `json:"name"`
+	Type                string               `json:"type"`
+	AbsoluteBoundingBox *AbsoluteBoundingBox `json:"absoluteBoundingBox,omitempty"`
+	Constraints         *Constraints         `json:"constraints,omitempty"`
+	Children            []FigmaNode          `json:"children,omitempty"`
+}
+
+// AbsoluteBoundingBox はmain.AbsoluteBoundingBoxの形状を写したものです。
+type AbsoluteBoundingBox struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// Constraints はmain.Constraintsの形状を写したものです。
+type Constraints struct {
+	Vertical   string `json:"vertical"`
+	Horizontal string `json:"horizontal"`
+}
+
+// Fill は解決済みの単色塗りつぶしです。
+type Fill struct {
+	Hex     string  `json:"hex"`
+	Opacity float64 `json:"opacity"`
+}
+
+// Stroke は解決済みの単色ストロークです。
+type Stroke struct {
+	Hex    string  `json:"hex"`
+	Weight float64 `json:"weight"`
+}
+
+// TextStyle はTEXTノードのタイポグラフィです。
+type TextStyle struct {
+	FontFamily   string  `json:"font_family"`
+	FontWeight   float64 `json:"font_weight"`
+	FontSizePx   float64 `json:"font_size_px"`
+	LineHeightPx float64 `json:"line_height_px"`
+}
+
+// LayerInfo はmain.LayerInfoの形状を写したものです。エクスポート機能で絶対配置と
+// 見た目（塗りつぶし/ストローク/フォント）をコード生成に渡せるよう、main側と同じ
+// 解決済みスタイルフィールドも持ちます。
+type LayerInfo struct {
+	ID                  string               `json:"id"`
+	Name                string               `json:"name"`
+	Type                string               `json:"type"`
+	ParentID            string               `json:"parent_id,omitempty"`
+	AbsoluteBoundingBox *AbsoluteBoundingBox `json:"absoluteBoundingBox,omitempty"`
+	Constraints         *Constraints         `json:"constraints,omitempty"`
+	Fill                *Fill                `json:"fill,omitempty"`
+	Stroke              *Stroke              `json:"stroke,omitempty"`
+	TextStyle           *TextStyle           `json:"text_style,omitempty"`
+}
+
+// FetchFileFunc はfileIDからFigmaFileを取得する関数です。main側のfetchFigmaFileを注入します。
+type FetchFileFunc func(fileID string) (*FigmaFile, error)
+
+// ExtractLayersFunc はページノードからフラットなLayerInfoを抽出する関数です。
+// main側のextractLayersを注入します。
+type ExtractLayersFunc func(page FigmaNode) []LayerInfo
+
+// FetchImageURLsFunc はfileIDとnodeIDsからレンダリング済み画像URL（サムネイル表示用）を
+// 取得する関数です。main側のfetchImageURLsを注入します。
+type FetchImageURLsFunc func(fileID string, nodeIDs []string) (map[string]string, error)
+
+// GenerateFunc はtargetとlayersから出力ファイル名・生成コードを返す関数です。
+// main側のcodegen.Lookup呼び出しを注入します。
+type GenerateFunc func(target string, layers []LayerInfo) (filename, content string, err error)
+
+// Server はpages/frames/layers/exportのAPIと埋め込みフロントエンドを提供するHTTPサーバーです。
+type Server struct {
+	fetchFile      FetchFileFunc
+	extractLayers  ExtractLayersFunc
+	fetchImageURLs FetchImageURLsFunc
+	generate       GenerateFunc
+	mux            *http.ServeMux
+}
+
+var pagesPathRe = regexp.MustCompile(`^/api/files/([^/]+)/pages$`)
+var framesPathRe = regexp.MustCompile(`^/api/files/([^/]+)/pages/([^/]+)/frames$`)
+var layersPathRe = regexp.MustCompile(`^/api/files/([^/]+)/pages/([^/]+)/layers$`)
+var exportPathRe = regexp.MustCompile(`^/api/files/([^/]+)/pages/([^/]+)/export$`)
+
+// New はfetchFile/extractLayers/fetchImageURLs/generateを使うServerを構築します。
+func New(fetchFile FetchFileFunc, extractLayers ExtractLayersFunc, fetchImageURLs FetchImageURLsFunc, generate GenerateFunc) *Server {
+	s := &Server{
+		fetchFile:      fetchFile,
+		extractLayers:  extractLayers,
+		fetchImageURLs: fetchImageURLs,
+		generate:       generate,
+		mux:            http.NewServeMux(),
+	}
+	s.routes()
+	return s
+}
+
+// routes はgorilla/mux風のパスパターンを持つAPIエンドポイントと、
+// 静的な選択UIフロントエンドを登録します。
+func (s *Server) routes() {
+	s.mux.HandleFunc("/api/files/", s.handleFilesAPI)
+
+	staticContent, err := fs.Sub(staticFS, "static")
+	if err != nil {
+		// staticディレクトリは埋め込みリソースとして常に存在するはずです。
+		panic(fmt.Sprintf("埋め込み静的ファイルの読み込みに失敗しました: %v", err))
+	}
+	s.mux.Handle("/", http.FileServer(http.FS(staticContent)))
+}
+
+// handleFilesAPI は /api/files/{id}/pages と /api/files/{id}/pages/{name}/frames、
+// /api/files/{id}/pages/{name}/layers、/api/files/{id}/pages/{name}/export を
+// パスパターンでディスパッチします。net/httpの素のServeMuxにはパス変数が無いため、
+// 正規表現で手動ルーティングしています。
+func (s *Server) handleFilesAPI(w http.ResponseWriter, r *http.Request) {
+	if m := pagesPathRe.FindStringSubmatch(r.URL.Path); m != nil {
+		s.handlePages(w, r, m[1])
+		return
+	}
+	if m := framesPathRe.FindStringSubmatch(r.URL.Path); m != nil {
+		s.handleFrames(w, r, m[1], m[2])
+		return
+	}
+	if m := exportPathRe.FindStringSubmatch(r.URL.Path); m != nil {
+		s.handleExport(w, r, m[1], m[2])
+		return
+	}
+	if m := layersPathRe.FindStringSubmatch(r.URL.Path); m != nil {
+		s.handleLayers(w, r, m[1], m[2])
+		return
+	}
+	http.NotFound(w, r)
+}
+
+type pagesResponse struct {
+	Pages []pageSummary `json:"pages"`
+}
+
+type pageSummary struct {
+	Name string `json:"name"`
+}
+
+// handlePages はfileID配下のトップレベルキャンバス（ページ）一覧を返します。
+func (s *Server) handlePages(w http.ResponseWriter, r *http.Request, fileID string) {
+	file, err := s.fetchFile(fileID)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+
+	resp := pagesResponse{}
+	for _, child := range file.Document.Children {
+		if child.Type == "CANVAS" {
+			resp.Pages = append(resp.Pages, pageSummary{Name: child.Name})
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleLayers はfileID配下の指定ページのレイヤー情報をJSONで返します。
+func (s *Server) handleLayers(w http.ResponseWriter, r *http.Request, fileID, pageName string) {
+	page, err := s.findPage(fileID, pageName)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	if page == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("ページ '%s' が見つかりませんでした", pageName))
+		return
+	}
+
+	layers := s.extractLayers(*page)
+	writeJSON(w, http.StatusOK, layers)
+}
+
+type framesResponse struct {
+	Frames []frameSummary `json:"frames"`
+}
+
+type frameSummary struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+}
+
+// handleFrames はpageName配下のトップレベルフレーム一覧を、サムネイルプレビュー用の
+// 画像URL付きで返します。フロントエンドの「書き出すフレームを選ぶ」トグルに使われます。
+func (s *Server) handleFrames(w http.ResponseWriter, r *http.Request, fileID, pageName string) {
+	page, err := s.findPage(fileID, pageName)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	if page == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("ページ '%s' が見つかりませんでした", pageName))
+		return
+	}
+
+	ids := make([]string, 0, len(page.Children))
+	for _, child := range page.Children {
+		ids = append(ids, child.ID)
+	}
+
+	thumbnails := map[string]string{}
+	if len(ids) > 0 {
+		thumbnails, err = s.fetchImageURLs(fileID, ids)
+		if err != nil {
+			writeError(w, http.StatusBadGateway, err)
+			return
+		}
+	}
+
+	resp := framesResponse{}
+	for _, child := range page.Children {
+		resp.Frames = append(resp.Frames, frameSummary{ID: child.ID, Name: child.Name, ThumbnailURL: thumbnails[child.ID]})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleExport はlayers.json（と、target指定があれば生成コード）をzipにまとめて返します。
+// framesクエリパラメータ（カンマ区切りのフレームID）で絞り込むと、選択したフレーム配下
+// だけがエクスポート対象になります。
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request, fileID, pageName string) {
+	target := r.URL.Query().Get("target")
+	if target == "" {
+		target = "json"
+	}
+	var frameIDs []string
+	if raw := r.URL.Query().Get("frames"); raw != "" {
+		frameIDs = strings.Split(raw, ",")
+	}
+
+	page, err := s.findPage(fileID, pageName)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err)
+		return
+	}
+	if page == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("ページ '%s' が見つかりませんでした", pageName))
+		return
+	}
+
+	layers := filterLayersByFrames(s.extractLayers(*page), frameIDs)
+
+	layersJSON, err := json.MarshalIndent(layers, "", "  ")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	files := map[string][]byte{"layers.json": layersJSON}
+	if target != "json" {
+		filename, content, err := s.generate(target, layers)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		files[filename] = []byte(content)
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-%s.zip"`, fileID, pageName))
+	if err := writeZip(w, files); err != nil {
+		fmt.Printf("zipエクスポートの書き込みに失敗しました: %v\n", err)
+	}
+}
+
+// findPage はfileID配下のfile取得とpageNameでの検索をまとめたヘルパーです。
+// ページが見つからない場合はnil, nilを返します（呼び出し元がNotFoundに変換します）。
+func (s *Server) findPage(fileID, pageName string) (*FigmaNode, error) {
+	file, err := s.fetchFile(fileID)
+	if err != nil {
+		return nil, err
+	}
+	for i, child := range file.Document.Children {
+		if child.Type == "CANVAS" && child.Name == pageName {
+			return &file.Document.Children[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// filterLayersByFrames はlayersのうち、frameIDsのいずれかのフレーム自身とその子孫
+// （およびページ自身のルートエントリ）だけを残します。frameIDsが空の場合はlayersをそのまま返します。
+func filterLayersByFrames(layers []LayerInfo, frameIDs []string) []LayerInfo {
+	if len(frameIDs) == 0 {
+		return layers
+	}
+	allowed := make(map[string]bool, len(frameIDs))
+	for _, id := range frameIDs {
+		allowed[id] = true
+	}
+	parentOf := make(map[string]string, len(layers))
+	for _, l := range layers {
+		parentOf[l.ID] = l.ParentID
+	}
+	isAllowed := func(id string) bool {
+		for cur := id; cur != ""; cur = parentOf[cur] {
+			if allowed[cur] {
+				return true
+			}
+		}
+		return false
+	}
+
+	var out []LayerInfo
+	for _, l := range layers {
+		if l.ParentID == "" || isAllowed(l.ID) {
+			out = append(out, l)
+		}
+	}
+	return out
+}
+
+// writeZip はfiles（ファイル名→内容）をzipアーカイブとしてwに書き出します。
+func writeZip(w io.Writer, files map[string][]byte) error {
+	zw := zip.NewWriter(w)
+	for name, content := range files {
+		f, err := zw.Create(name)
+		if err != nil {
+			return err
+		}
+		if _, err := f.Write(content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// ListenAndServe はaddr（例: ":8080"）でサーバーを起動します。
+func (s *Server) ListenAndServe(addr string) error {
+	fmt.Printf("選択UIを起動しました: http://localhost%s\n", addr)
+	return http.ListenAndServe(addr, s.mux)
+}
+
+// NormalizeAddr は "8080" のようなポートのみの指定を ":8080" に補完します。
+func NormalizeAddr(addr string) string {
+	if !strings.Contains(addr, ":") {
+		return ":" + addr
+	}
+	return addr
+}
@@ -0,0 +1,41 @@
+package server
+
+import "testing"
+
+func TestFilterLayersByFramesKeepsRootAndSelectedSubtree(t *testing.T) {
+	layers := []LayerInfo{
+		{ID: "page"},
+		{ID: "frameA", ParentID: "page"},
+		{ID: "frameA-child", ParentID: "frameA"},
+		{ID: "frameB", ParentID: "page"},
+		{ID: "frameB-child", ParentID: "frameB"},
+	}
+
+	got := filterLayersByFrames(layers, []string{"frameA"})
+
+	ids := make(map[string]bool, len(got))
+	for _, l := range got {
+		ids[l.ID] = true
+	}
+
+	for _, want := range []string{"page", "frameA", "frameA-child"} {
+		if !ids[want] {
+			t.Errorf("filterLayersByFrames() missing expected layer %q, got %v", want, ids)
+		}
+	}
+	for _, unwanted := range []string{"frameB", "frameB-child"} {
+		if ids[unwanted] {
+			t.Errorf("filterLayersByFrames() unexpectedly kept %q", unwanted)
+		}
+	}
+}
+
+func TestFilterLayersByFramesReturnsAllWhenNoFramesSelected(t *testing.T) {
+	layers := []LayerInfo{{ID: "page"}, {ID: "frameA", ParentID: "page"}}
+
+	got := filterLayersByFrames(layers, nil)
+
+	if len(got) != len(layers) {
+		t.Errorf("filterLayersByFrames(nil) = %d layers, want %d (no filtering)", len(got), len(layers))
+	}
+}
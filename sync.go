@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/nekoPhysics/figma_to_codev1/auth"
+	"github.com/nekoPhysics/figma_to_codev1/cache"
+	"github.com/nekoPhysics/figma_to_codev1/figmaclient"
+)
+
+// figmaVersionsResponse は GET /v1/files/{key}/versions のレスポンスです。
+type figmaVersionsResponse struct {
+	Versions []struct {
+		ID        string `json:"id"`
+		CreatedAt string `json:"created_at"`
+	} `json:"versions"`
+}
+
+// fetchLatestVersionID は対象ファイルの最新バージョンIDを返します。
+// Figmaのversionsエンドポイントは新しい順に並んでいるため先頭を使います。
+func fetchLatestVersionID(client *figmaclient.Client, tokenSource *auth.TokenSource, fileID string) (string, error) {
+	apiURL := fmt.Sprintf("https://api.figma.com/v1/files/%s/versions", fileID)
+	req, err := http.NewRequest("GET", apiURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("versions APIリクエストの作成に失敗しました: %w", err)
+	}
+	if err := tokenSource.SetHeader(req); err != nil {
+		return "", err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("versions APIリクエストの実行に失敗しました: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("versions APIがエラーを返しました: %s\n%s", resp.Status, string(body))
+	}
+
+	var parsed figmaVersionsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return "", fmt.Errorf("versions APIレスポンスのデコードに失敗しました: %w", err)
+	}
+	if len(parsed.Versions) == 0 {
+		return "", fmt.Errorf("versions APIが空の結果を返しました")
+	}
+	return parsed.Versions[0].ID, nil
+}
+
+// figmaNodesResponse は GET /v1/files/{key}/nodes のレスポンスです。
+type figmaNodesResponse struct {
+	Nodes map[string]struct {
+		Document FigmaNode `json:"document"`
+	} `json:"nodes"`
+}
+
+// fetchNodes は変更されたトップレベルフレーム（キャンバス子ノード）のサブツリーのみを
+// GET /v1/files/{key}/nodes?ids=... で再取得します。
+func fetchNodes(client *figmaclient.Client, tokenSource *auth.TokenSource, fileID string, nodeIDs []string) ([]FigmaNode, error) {
+	if len(nodeIDs) == 0 {
+		return nil, nil
+	}
+
+	apiURL := fmt.Sprintf("https://api.figma.com/v1/files/%s/nodes?ids=%s", fileID, strings.Join(nodeIDs, ","))
+	req, err := http.NewRequest("GET", apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("nodes APIリクエストの作成に失敗しました: %w", err)
+	}
+	if err := tokenSource.SetHeader(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("nodes APIリクエストの実行に失敗しました: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("nodes APIがエラーを返しました: %s\n%s", resp.Status, string(body))
+	}
+
+	var parsed figmaNodesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("nodes APIレスポンスのデコードに失敗しました: %w", err)
+	}
+
+	nodes := make([]FigmaNode, 0, len(nodeIDs))
+	for _, id := range nodeIDs {
+		if entry, ok := parsed.Nodes[id]; ok {
+			nodes = append(nodes, entry.Document)
+		}
+	}
+	return nodes, nil
+}
+
+// canvasModifiedTimes はページ直下のトップレベルフレームごとのlastModifiedを集めます。
+func canvasModifiedTimes(page FigmaNode) map[string]string {
+	modified := make(map[string]string, len(page.Children))
+	for _, child := range page.Children {
+		modified[child.ID] = child.LastModified
+	}
+	return modified
+}
+
+// mergeLayers はcachedの抽出結果のうち、staleFrameIDsに含まれるフレーム配下のレイヤーを
+// freshLayersの内容で置き換えます。削除されたフレームについてはfreshLayersに対応する
+// データが存在しないため、そのサブツリーはキャッシュからも取り除かれるだけになります。
+// 変更されていないフレームのレイヤーはそのまま残します。
+func mergeLayers(cachedLayers, freshLayers []LayerInfo, staleFrameIDs []string) []LayerInfo {
+	changed := make(map[string]bool, len(staleFrameIDs))
+	for _, id := range staleFrameIDs {
+		changed[id] = true
+	}
+
+	// changed配下（変更されたフレーム自身を含む）のレイヤーIDをcachedLayersの親子関係から洗い出す。
+	staleIDs := make(map[string]bool)
+	parentOf := make(map[string]string, len(cachedLayers))
+	for _, l := range cachedLayers {
+		parentOf[l.ID] = l.ParentID
+	}
+	isDescendant := func(id string) bool {
+		for cur := id; cur != ""; cur = parentOf[cur] {
+			if changed[cur] {
+				return true
+			}
+		}
+		return false
+	}
+	for _, l := range cachedLayers {
+		if isDescendant(l.ID) {
+			staleIDs[l.ID] = true
+		}
+	}
+
+	merged := make([]LayerInfo, 0, len(cachedLayers)+len(freshLayers))
+	for _, l := range cachedLayers {
+		if !staleIDs[l.ID] {
+			merged = append(merged, l)
+		}
+	}
+	merged = append(merged, freshLayers...)
+	return merged
+}
+
+// extractLayersIncremental はキャッシュが使える場合、変更されたトップレベルフレームだけを
+// nodes APIで再取得してキャッシュ済みレイヤーにマージします。削除されたトップレベル
+// フレームがあればそのサブツリーもキャッシュから取り除きます。キャッシュが使えない場合は
+// ページ全体を通常通り抽出します。
+func extractLayersIncremental(client *figmaclient.Client, tokenSource *auth.TokenSource, fileID string, targetPage FigmaNode, cached cache.Entry, useCache bool) ([]LayerInfo, error) {
+	if !useCache {
+		var layers []LayerInfo
+		extractLayers(targetPage, "", &layers)
+		return layers, nil
+	}
+
+	cachedLayers, err := loadCachedLayers(cached)
+	if err != nil {
+		return nil, err
+	}
+	if cachedLayers == nil {
+		var layers []LayerInfo
+		extractLayers(targetPage, "", &layers)
+		return layers, nil
+	}
+
+	current := canvasModifiedTimes(targetPage)
+	changedFrameIDs := cache.ChangedCanvases(cached, current)
+	deletedFrameIDs := cache.DeletedCanvases(cached, current)
+	if len(changedFrameIDs) == 0 && len(deletedFrameIDs) == 0 {
+		return cachedLayers, nil
+	}
+
+	changedNodes, err := fetchNodes(client, tokenSource, fileID, changedFrameIDs)
+	if err != nil {
+		return nil, fmt.Errorf("変更されたフレームの再取得に失敗しました: %w", err)
+	}
+
+	var freshLayers []LayerInfo
+	for _, node := range changedNodes {
+		extractLayers(node, targetPage.ID, &freshLayers)
+	}
+
+	staleFrameIDs := append(append([]string{}, changedFrameIDs...), deletedFrameIDs...)
+	return mergeLayers(cachedLayers, freshLayers, staleFrameIDs), nil
+}
+
+// loadCachedLayers はEntryのLayersフィールドを[]LayerInfoへデコードします。
+func loadCachedLayers(entry cache.Entry) ([]LayerInfo, error) {
+	if len(entry.Layers) == 0 {
+		return nil, nil
+	}
+	var layers []LayerInfo
+	if err := json.Unmarshal(entry.Layers, &layers); err != nil {
+		return nil, fmt.Errorf("キャッシュ済みレイヤー情報のデコードに失敗しました: %w", err)
+	}
+	return layers, nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import "testing"
+
+func TestMergeLayersReplacesOnlyChangedFrameSubtree(t *testing.T) {
+	cached := []LayerInfo{
+		{ID: "page", Name: "Page"},
+		{ID: "frameA", Name: "FrameA", ParentID: "page"},
+		{ID: "frameA-child", Name: "Old", ParentID: "frameA"},
+		{ID: "frameB", Name: "FrameB", ParentID: "page"},
+		{ID: "frameB-child", Name: "Untouched", ParentID: "frameB"},
+	}
+	fresh := []LayerInfo{
+		{ID: "frameA", Name: "FrameA", ParentID: "page"},
+		{ID: "frameA-child", Name: "New", ParentID: "frameA"},
+	}
+
+	merged := mergeLayers(cached, fresh, []string{"frameA"})
+
+	byID := make(map[string]LayerInfo, len(merged))
+	for _, l := range merged {
+		byID[l.ID] = l
+	}
+
+	if got := byID["frameA-child"].Name; got != "New" {
+		t.Errorf("frameA-child.Name = %q, want %q (changed frame should be replaced)", got, "New")
+	}
+	if got := byID["frameB-child"].Name; got != "Untouched" {
+		t.Errorf("frameB-child.Name = %q, want %q (unchanged frame should be preserved)", got, "Untouched")
+	}
+	if _, ok := byID["frameB"]; !ok {
+		t.Error("frameB should still be present in the merged result")
+	}
+}
+
+func TestMergeLayersDropsDeletedFrameSubtree(t *testing.T) {
+	cached := []LayerInfo{
+		{ID: "page", Name: "Page"},
+		{ID: "frameA", Name: "FrameA", ParentID: "page"},
+		{ID: "frameA-child", Name: "Untouched", ParentID: "frameA"},
+		{ID: "frameB", Name: "FrameB", ParentID: "page"},
+		{ID: "frameB-child", Name: "Deleted", ParentID: "frameB"},
+	}
+
+	merged := mergeLayers(cached, nil, []string{"frameB"})
+
+	for _, l := range merged {
+		if l.ID == "frameB" || l.ID == "frameB-child" {
+			t.Errorf("mergeLayers() kept %q from a deleted frame subtree", l.ID)
+		}
+	}
+	if byID := func() map[string]bool {
+		m := make(map[string]bool, len(merged))
+		for _, l := range merged {
+			m[l.ID] = true
+		}
+		return m
+	}(); !byID["frameA"] || !byID["frameA-child"] {
+		t.Error("mergeLayers() should preserve the untouched frame subtree")
+	}
+}
+
+func TestCanvasModifiedTimesCollectsTopLevelChildren(t *testing.T) {
+	page := FigmaNode{
+		ID: "page",
+		Children: []FigmaNode{
+			{ID: "frameA", LastModified: "2026-01-01T00:00:00Z"},
+			{ID: "frameB", LastModified: "2026-01-02T00:00:00Z"},
+		},
+	}
+
+	got := canvasModifiedTimes(page)
+
+	want := map[string]string{
+		"frameA": "2026-01-01T00:00:00Z",
+		"frameB": "2026-01-02T00:00:00Z",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("canvasModifiedTimes() = %v, want %v", got, want)
+	}
+	for id, modified := range want {
+		if got[id] != modified {
+			t.Errorf("canvasModifiedTimes()[%q] = %q, want %q", id, got[id], modified)
+		}
+	}
+}
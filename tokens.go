@@ -0,0 +1,209 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/nekoPhysics/figma_to_codev1/auth"
+	"github.com/nekoPhysics/figma_to_codev1/figmaclient"
+)
+
+// figmaStylesResponse は GET /v1/files/{key}/styles のレスポンスです。
+// ファイル本体のJSONに含まれる"styles"マップとは異なり、各スタイルの定義元ノードID
+// (node_id)が含まれるため、実際の色/タイポグラフィ/シャドウの値を解決できます。
+type figmaStylesResponse struct {
+	Meta struct {
+		Styles []figmaStyleEntry `json:"styles"`
+	} `json:"meta"`
+}
+
+type figmaStyleEntry struct {
+	Key       string `json:"key"`
+	NodeID    string `json:"node_id"`
+	StyleType string `json:"style_type"`
+	Name      string `json:"name"`
+}
+
+// fetchStyleEntries は GET /v1/files/{key}/styles を呼び出し、ファイル内の
+// 全スタイル定義（色・テキスト・エフェクト・グリッド）を取得します。
+func fetchStyleEntries(client *figmaclient.Client, tokenSource *auth.TokenSource, fileID string) ([]figmaStyleEntry, error) {
+	apiURL := fmt.Sprintf("https://api.figma.com/v1/files/%s/styles", fileID)
+	req, err := http.NewRequest("GET", apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("styles APIリクエストの作成に失敗しました: %w", err)
+	}
+	if err := tokenSource.SetHeader(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("styles APIリクエストの実行に失敗しました: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("styles APIがエラーを返しました: %s\n%s", resp.Status, string(body))
+	}
+
+	var parsed figmaStylesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("styles APIレスポンスのデコードに失敗しました: %w", err)
+	}
+	return parsed.Meta.Styles, nil
+}
+
+// dtcgToken はW3C Design Tokens Community Groupフォーマットの1トークンです。
+type dtcgToken struct {
+	Value interface{} `json:"$value"`
+	Type  string      `json:"$type"`
+}
+
+// BuildDesignTokens はfileID内のスタイル定義を解決し、DTCG形式の
+// ネストしたトークンツリーを返します。スタイル名の"brand/primary/500"のような
+// "/"区切りはFigmaのフォルダ構造に対応し、そのままネストしたオブジェクトになります。
+// 値を解決できないスタイル（GRIDスタイル、SOLID以外の塗りつぶしだけを持つFILLスタイル、
+// 定義元ノードが取得できなかったスタイルなど）はトークンに含めません。
+func BuildDesignTokens(client *figmaclient.Client, tokenSource *auth.TokenSource, fileID string) (map[string]interface{}, error) {
+	entries, err := fetchStyleEntries(client, tokenSource, fileID)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return map[string]interface{}{}, nil
+	}
+
+	nodeIDs := make([]string, 0, len(entries))
+	for _, e := range entries {
+		nodeIDs = append(nodeIDs, e.NodeID)
+	}
+
+	nodes, err := fetchNodes(client, tokenSource, fileID, nodeIDs)
+	if err != nil {
+		return nil, fmt.Errorf("スタイル定義元ノードの取得に失敗しました: %w", err)
+	}
+	nodesByID := make(map[string]FigmaNode, len(nodes))
+	for _, n := range nodes {
+		nodesByID[n.ID] = n
+	}
+
+	tokens := map[string]interface{}{}
+	for _, entry := range entries {
+		node, ok := nodesByID[entry.NodeID]
+		if !ok {
+			continue
+		}
+		token, ok := resolveStyleToken(entry, node)
+		if !ok {
+			continue
+		}
+		insertToken(tokens, tokenCategory(entry.StyleType), entry.Name, token)
+	}
+	return tokens, nil
+}
+
+// tokenCategory はFigmaのstyle_typeをDTCGのトップレベルカテゴリ名に対応付けます。
+func tokenCategory(styleType string) string {
+	switch styleType {
+	case "FILL":
+		return "color"
+	case "TEXT":
+		return "typography"
+	case "EFFECT":
+		return "shadow"
+	case "GRID":
+		return "grid"
+	default:
+		return "other"
+	}
+}
+
+// resolveStyleToken はスタイル定義元ノードからDTCGトークンの値を組み立てます。
+func resolveStyleToken(entry figmaStyleEntry, node FigmaNode) (dtcgToken, bool) {
+	switch entry.StyleType {
+	case "FILL":
+		for _, fill := range node.Fills {
+			if fill.Type == "SOLID" && fill.Color != nil {
+				return dtcgToken{Value: colorToHex(*fill.Color), Type: "color"}, true
+			}
+		}
+	case "TEXT":
+		if node.Style != nil {
+			return dtcgToken{
+				Value: map[string]interface{}{
+					"fontFamily": node.Style.FontFamily,
+					"fontWeight": node.Style.FontWeight,
+					"fontSize":   node.Style.FontSize,
+					"lineHeight": node.Style.LineHeightPx,
+				},
+				Type: "typography",
+			}, true
+		}
+	case "EFFECT":
+		for _, effect := range node.Effects {
+			if effect.Type == "DROP_SHADOW" || effect.Type == "INNER_SHADOW" {
+				value := map[string]interface{}{
+					"radius": effect.Radius,
+					"spread": effect.Spread,
+				}
+				if effect.Color != nil {
+					value["color"] = colorToHex(*effect.Color)
+				}
+				if effect.Offset != nil {
+					value["offsetX"] = effect.Offset.X
+					value["offsetY"] = effect.Offset.Y
+				}
+				return dtcgToken{Value: value, Type: "shadow"}, true
+			}
+		}
+	}
+	return dtcgToken{}, false
+}
+
+// colorToHex はFigmaの0〜1のRGBAを "#rrggbbaa" 形式に変換します。
+func colorToHex(c FigmaColor) string {
+	toByte := func(v float64) int {
+		if v < 0 {
+			v = 0
+		}
+		if v > 1 {
+			v = 1
+		}
+		return int(v*255 + 0.5)
+	}
+	r, g, b, a := toByte(c.R), toByte(c.G), toByte(c.B), toByte(c.A)
+	return "#" + hexByte(r) + hexByte(g) + hexByte(b) + hexByte(a)
+}
+
+func hexByte(v int) string {
+	s := strconv.FormatInt(int64(v), 16)
+	if len(s) == 1 {
+		s = "0" + s
+	}
+	return s
+}
+
+// insertToken はFigmaのスタイル名（"brand/primary/500"のような"/"区切り）に沿って
+// tokensにネストしたオブジェクトを作り、末尾にtokenを書き込みます。
+func insertToken(tokens map[string]interface{}, category, name string, token dtcgToken) {
+	segments := append([]string{category}, strings.Split(name, "/")...)
+
+	cursor := tokens
+	for i, seg := range segments {
+		if i == len(segments)-1 {
+			cursor[seg] = map[string]interface{}{"$value": token.Value, "$type": token.Type}
+			return
+		}
+		next, ok := cursor[seg].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			cursor[seg] = next
+		}
+		cursor = next
+	}
+}